@@ -0,0 +1,38 @@
+package async
+
+import "context"
+
+// FromErrorChan adapts a component whose lifecycle is exposed as a
+// Start function returning an error channel into a Job.
+// Job.Run calls start and blocks until the channel delivers an error or
+// is closed. A close without an error is treated as a clean exit.
+// Job.Close calls stop.
+func FromErrorChan(start func() <-chan error, stop func() error) *Job {
+	return &Job{
+		Run: func() error {
+			e, ok := <-start()
+			if !ok {
+				return nil
+			}
+			return e
+		},
+		Close: stop,
+	}
+}
+
+// ErrGroup is the method set the job needs from a group of goroutines,
+// as provided by *errgroup.Group from golang.org/x/sync/errgroup.
+type ErrGroup interface {
+	Go(f func() error)
+}
+
+// Go runs the job in g as ExecuteContext does, so the job closes when
+// ctx is done and its error is returned from g's function. Passing the
+// context from errgroup.WithContext ties the job to the rest of the
+// group: another function failing closes the job, and the job failing
+// cancels the others.
+func (j *Job) Go(g ErrGroup, ctx context.Context) {
+	g.Go(func() error {
+		return j.ExecuteContext(ctx)
+	})
+}
@@ -0,0 +1,118 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jharshman/async"
+)
+
+// fakeComponent exposes its lifecycle as Start() <-chan error.
+type fakeComponent struct {
+	errs    chan error
+	stopped bool
+}
+
+func (f *fakeComponent) Start() <-chan error {
+	return f.errs
+}
+
+func (f *fakeComponent) Stop() error {
+	f.stopped = true
+	close(f.errs)
+	return nil
+}
+
+func TestFromErrorChan(t *testing.T) {
+	c := &fakeComponent{errs: make(chan error)}
+	job := async.FromErrorChan(c.Start, c.Stop)
+
+	go func() {
+		<-time.After(time.Millisecond * 100)
+		job.SignalToClose()
+	}()
+
+	err := job.Execute()
+	if err != nil {
+		t.Error(err)
+	}
+	if !c.stopped {
+		t.Error("expected Stop to be called")
+	}
+}
+
+func TestFromErrorChan_WithErrors(t *testing.T) {
+	c := &fakeComponent{errs: make(chan error, 1)}
+	c.errs <- errors.New("some error")
+	job := async.FromErrorChan(c.Start, c.Stop)
+
+	// error expected here
+	err := job.Execute()
+	if err == nil {
+		t.Error(err)
+	}
+}
+
+// fakeErrGroup mimics an errgroup.Group derived with WithContext: the
+// first error cancels the shared context, and Wait returns it.
+type fakeErrGroup struct {
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	once   sync.Once
+	err    error
+}
+
+func (g *fakeErrGroup) Go(f func() error) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		if err := f(); err != nil {
+			g.once.Do(func() {
+				g.err = err
+				g.cancel()
+			})
+		}
+	}()
+}
+
+func (g *fakeErrGroup) Wait() error {
+	g.wg.Wait()
+	return g.err
+}
+
+func TestJob_Go(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	g := &fakeErrGroup{cancel: cancel}
+
+	closed := make(chan struct{})
+	job := async.Job{
+		RunCtx: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		Close: func() error {
+			close(closed)
+			return nil
+		},
+	}
+	job.Go(g, ctx)
+
+	errFailed := errors.New("some error")
+	g.Go(func() error {
+		return errFailed
+	})
+
+	// error expected here
+	if err := g.Wait(); err != errFailed {
+		t.Errorf("expected %v, got %v", errFailed, err)
+	}
+	select {
+	case <-closed:
+	default:
+		t.Error("expected the job to close once the group failed")
+	}
+}
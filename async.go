@@ -15,62 +15,391 @@ These fields are functions that will control the running and safe closing of you
 		},
 	}
 
-Running an HTTP server with async.Job might look like the following:
+Running an HTTP server is common enough to have its own helper. async.HTTPServerJob
+returns a Job that serves s and, on close, shuts it down, giving open connections up to
+the timeout to finish:
 
-	myJob := async.Job{
-		Run: func() error {
-			return http.ListenAndServe()
-		},
-		Close: func() error {
-			return s.Shutdown(context.Background())
-		},
-	}
+	myJob := async.HTTPServerJob(&s, 10*time.Second)
 
 	myJob.Execute()
 
 By default, the function defined for async.Job.Close will trigger when a syscall.SIGINT or
-syscall.SIGTERM is received. You can modify these defaults by setting your own on the async.Job.
+syscall.SIGTERM is received, or os.Interrupt on Windows. You can modify these defaults by setting
+your own on the async.Job.
+
+	myJob := async.HTTPServerJob(&s, 10*time.Second, async.WithSignals(syscall.SIGHUP))
+
+	myJob.Execute()
+
+A server can also be wired up by hand. Once Close has called Shutdown, ListenAndServe
+returns http.ErrServerClosed; the job has stopped as asked, so that error is not reported.
 
 	myJob := async.Job{
 		Run: func() error {
-			return http.ListenAndServe()
+			return s.ListenAndServe()
 		},
 		Close: func() error {
 			return s.Shutdown(context.Background())
 		},
-		Signals: []os.Signal{syscall.SIGHUP},
 	}
 
-	myJob.Execute()
+To run several jobs together, add them to an async.JobGroup. The group listens for signals
+once and closes every member when a signal arrives or any member fails.
 
+	var g async.JobGroup
+	g.Add(&httpJob)
+	g.Add(&metricsJob)
+
+	g.Run()
 */
 package async
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
-	"os/signal"
-	"syscall"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// ErrCloseTimeout is reported when Job.Close does not finish within
+// Job.CloseTimeout.
+var ErrCloseTimeout = errors.New("close timed out")
+
+// ErrForcedShutdown is returned by Execute when a second signal arrives
+// before the close path has finished.
+var ErrForcedShutdown = errors.New("forced shutdown")
+
+// ErrAlreadyStarted is returned when starting a job that is
+// already running.
+var ErrAlreadyStarted = errors.New("job already started")
+
+// ErrNotStarted is returned when signaling a job that
+// has not been started.
+var ErrNotStarted = errors.New("job not started")
+
+// ErrDeadlineExceeded is returned by Execute when the job was closed
+// because it ran for longer than Job.MaxRuntime.
+var ErrDeadlineExceeded = errors.New("max runtime exceeded")
+
+// ErrStartupTimeout is returned by Execute when the job was closed
+// because it did not become ready within Job.StartupTimeout, and by
+// Handoff when the new job was not ready in time.
+var ErrStartupTimeout = errors.New("job not ready within startup timeout")
+
+// ErrReloadFatal is wrapped by a Job.Reload error that should close the
+// job rather than leave it running.
+var ErrReloadFatal = errors.New("fatal reload error")
+
+// ErrCloseRepeated is returned with Job.StrictClose set when Close is
+// called again after it has already been called for the same run.
+var ErrCloseRepeated = errors.New("close called more than once")
+
+// ErrShutdownTimeout is returned by JobGroup.Run when its members have
+// not all finished closing within JobGroup.ShutdownTimeout.
+var ErrShutdownTimeout = errors.New("group shutdown timed out")
+
+// defaultTelemetryFlushTimeout is used when Job.TelemetryFlushTimeout is zero.
+const defaultTelemetryFlushTimeout = 5 * time.Second
+
 type SafeCloser interface {
 	RunWithClose() (sig, ack chan int, err chan error)
 }
 
+// Runnable is implemented by the package's runners, Job, JobGroup and
+// Supervisor, so that they can be driven alike. Execute blocks until the
+// runner has closed; ExecuteContext also closes it when ctx is done.
+type Runnable interface {
+	Execute() error
+	ExecuteContext(ctx context.Context) error
+}
+
+var (
+	_ Runnable = (*Job)(nil)
+	_ Runnable = (*JobGroup)(nil)
+	_ Runnable = (*Supervisor)(nil)
+)
+
 type Job struct {
+	// Name identifies the job in Status and JobGroup.StatusJSON.
+	Name string
+
 	// Run And Close functions.
 	// Both required iff using Execute() or RunWithClose().
 	Run   func() error
 	Close func() error
 
+	// RunCtx and CloseCtx are context-aware alternatives to Run and Close
+	// and take precedence over them when set. The context passed to RunCtx
+	// is cancelled right before the close path begins, so a well-behaved
+	// RunCtx can return on its own. If CloseTimeout is set, the context
+	// also gains a deadline at that moment, CloseTimeout from the close
+	// trigger, telling RunCtx how long it has left to wind down.
+	RunCtx   func(ctx context.Context) error
+	CloseCtx func(ctx context.Context) error
+
+	// RunWithCleanup is an alternative to Run that is handed a registrar
+	// for cleanup functions. Run may register a cleanup as each resource is
+	// initialized. If RunWithCleanup returns an error, the registered
+	// cleanups run in LIFO order before the error is reported. Otherwise
+	// they run in LIFO order after Close. Close is optional when
+	// RunWithCleanup is set.
+	RunWithCleanup func(cleanup func(fn func() error)) error
+
+	// RunWithStop is an alternative to Run for functions that watch a
+	// context, a stop channel, or both. When the close path begins, ctx is
+	// cancelled and stop is closed at the same moment, before Close is
+	// called.
+	RunWithStop func(ctx context.Context, stop <-chan struct{}) error
+
+	// CloseSignal is an alternative to Close for jobs that close
+	// differently depending on the signal Execute received, for instance
+	// SIGTERM from an orchestrator against SIGINT from a terminal. It is
+	// passed nil when the close was not triggered by a signal. CloseCtx
+	// takes precedence over it.
+	CloseSignal func(s os.Signal) error
+
+	// CloseWithReason is an alternative to Close for jobs whose cleanup
+	// depends on what triggered the close, for instance to skip flushing
+	// after Run failed. CloseCtx and CloseSignal take precedence over it.
+	CloseWithReason func(r CloseReason) error
+
+	// RunReady is an alternative to Run for services that take a while to
+	// become ready to serve, for instance to bind a port. It is handed a
+	// ready function to call once they are; the channel returned by Ready
+	// is closed on the first call. With any other run variant, the job
+	// counts as ready as soon as it is launched.
+	RunReady func(ready func()) error
+
+	// RunControlled is an alternative to Run for polling or queue
+	// processing loops that can be paused without closing the job. The
+	// loop should call ctrl.Wait between iterations, which blocks while
+	// the job is paused; see Pause.
+	RunControlled func(ctrl *Control) error
+
+	// Drain, if set, runs at the start of the close path, before Close.
+	// It should stop accepting new work and wait for in-flight work to
+	// finish. Its context carries the CloseTimeout deadline, which Drain
+	// and Close share. If Drain fails, its error is reported and Close
+	// still runs.
+	Drain func(ctx context.Context) error
+
+	// Backlog, if set, reports how many items Drain has yet to process.
+	// If the CloseTimeout deadline passes with items left, the count is
+	// reported in a DrainError alongside ErrCloseTimeout.
+	Backlog func() int
+
+	// Logger receives log lines for key lifecycle transitions: signals
+	// received, the start and end of the close path, and errors.
+	// Nothing is logged when it is nil.
+	Logger Logger
+
+	// Metrics, if set, receives the duration of each run attempt and of
+	// the close path, and a count of restarts.
+	Metrics Metrics
+
+	// OnStart and OnStop are optional lifecycle hooks. OnStart is called
+	// right after Run is launched. OnStop is called right after Close has
+	// completed, just before ack is sent; it is not called if Close times
+	// out.
+	OnStart func()
+	OnStop  func()
+
+	// SkipCloseOnPanic makes a panic in Run fatal to teardown: the panic
+	// is recovered and reported as usual, but Close and OnStop are
+	// skipped and ack is sent at once, so that teardown does not carry on
+	// from a corrupted state. Finally still runs.
+	SkipCloseOnPanic bool
+
+	// Finally, if set, is called at the very end of every close path:
+	// after Close, after Close has timed out, and when Close is skipped
+	// by SkipCloseOnPanic. It is called before ack is sent, except on a
+	// timeout, when no ack follows.
+	Finally func()
+
+	// OnStateChange, if set, is called with each new State of the job as
+	// it happens, from the goroutine driving the close path. See also
+	// StateChanges.
+	OnStateChange func(s State)
+
+	// CloseTimeout bounds how long the close path may take. If Close has
+	// not finished in time, ErrCloseTimeout is sent on the "err" channel
+	// and no ack is sent. The context passed to CloseCtx carries the same
+	// deadline. Zero waits indefinitely.
+	CloseTimeout time.Duration
+
+	// ErrBuffer is the capacity of the "err" channel returned by
+	// RunWithClose, for callers that read it late. It is never less
+	// than the two errors a run can report, which is the default.
+	ErrBuffer int
+
+	// AckBuffer is the capacity of the "ack" channel, and of the phase
+	// channel returned by RunWithPhases. It defaults to 1, which is all
+	// the single ack of RunWithClose needs. A RunWithPhases caller must
+	// receive every phase ack, as the close path waits for room to send
+	// each; one that only reads them once the job is done needs room
+	// for all of them, an AckBuffer of 3.
+	AckBuffer int
+
+	// ErrHistory, if positive, is how many of the latest errors the job
+	// keeps for RecentErrors, across runs and restarts.
+	ErrHistory int
+
+	// StrictClose is a development aid for finding code paths that close
+	// a job twice. Close is only ever called once per run; by default any
+	// later attempt is silently ignored, but with StrictClose set it
+	// returns ErrCloseRepeated instead. That includes RunMain's deferred
+	// close after appMain has executed the job.
+	StrictClose bool
+
 	// Signals is a slice of os.Signal to notify on.
-	// This is used by Execute(). Defaults to SIGINT and SIGTERM.
+	// This is used by Execute(). Defaults to SIGINT and SIGTERM, or
+	// os.Interrupt alone on Windows, where SIGTERM is never delivered.
 	Signals []os.Signal
 
+	// SignalHandlers maps informational signals, such as SIGUSR1, to
+	// functions Execute calls each time one arrives. They do not close
+	// the job, and they keep being handled while Close runs, for example
+	// to report the progress of the shutdown itself. A handler runs on
+	// Execute's goroutine and should return quickly. A signal may not
+	// appear both here and in Signals, or among its defaults.
+	SignalHandlers map[os.Signal]func()
+
+	// ReloadSignals are signals, such as SIGHUP, that ask the job to
+	// reload its configuration rather than close. Each one received by
+	// Execute calls Reload and the job keeps running. A Reload error is
+	// logged and returned by Execute once the job closes; an error
+	// wrapping ErrReloadFatal also starts the close path. Reload is
+	// required when ReloadSignals is set, and a signal may not be both a
+	// reload signal and one in Signals or SignalHandlers.
+	ReloadSignals []os.Signal
+	Reload        func() error
+
+	// MaxRuntime, if set, is the longest a job may run under Execute.
+	// If it is still running once MaxRuntime has passed since launch, the
+	// close path begins, regardless of MinUptime, and Execute returns
+	// ErrDeadlineExceeded. CloseTimeout still bounds the close path that
+	// follows.
+	MaxRuntime time.Duration
+
+	// StartupTimeout, if set, is how long a job run by Execute has to
+	// become ready after launch, as described on RunReady, including
+	// each launch by Restart. If it is not ready by then, the close path
+	// begins, regardless of MinUptime, and Execute returns
+	// ErrStartupTimeout. Only RunReady becomes ready later than launch,
+	// so StartupTimeout has no effect on the other run variants.
+	StartupTimeout time.Duration
+
+	// MinUptime is the minimum time a job runs before a close trigger
+	// received by Execute takes effect. A trigger arriving earlier is
+	// deferred until MinUptime has elapsed, unless a signal arrives while
+	// it is, escalating the close: the close path then begins at once.
+	// Zero disables the delay.
+	MinUptime time.Duration
+
+	// OnClosingSoon, if set, is called once when a close trigger is
+	// deferred by MinUptime, with the time remaining before the close
+	// path begins. Use it to warn connected clients.
+	OnClosingSoon func(in time.Duration)
+
+	// OnSignal, if set, is called with each signal Execute receives that
+	// closes the job or forces its shutdown, before acting on it. A
+	// JobGroup calls it on each started member with the signal that
+	// closed the group.
+	OnSignal func(s os.Signal)
+
+	// TelemetryFlush, if set, runs as the very last step of Execute, after
+	// Close, any cleanups and Finally, on every return path including
+	// errors, panics and ErrCloseTimeout. Only a forced shutdown does not
+	// wait for the close path ahead of it. It is given a context bounded
+	// by TelemetryFlushTimeout (default 5s) so that exporting shutdown
+	// spans or metrics cannot hold up exit.
+	TelemetryFlush        func(ctx context.Context) error
+	TelemetryFlushTimeout time.Duration
+
+	// MinFreeDiskBytes, when non-zero, has Execute check the free space on
+	// the filesystem containing WatchPath every DiskCheckInterval (default
+	// 10s). If it falls below the threshold the job is closed gracefully
+	// and Execute returns ErrLowDisk.
+	MinFreeDiskBytes  uint64
+	WatchPath         string
+	DiskCheckInterval time.Duration
+
+	// Next is executed once this job has closed cleanly, forming a chain.
+	// By default an error from any job in the chain stops it and is
+	// returned wrapped with the position of the failing job; ChainPolicy,
+	// read from the head of the chain, can change that. A chain must not
+	// loop.
+	Next        *Job
+	ChainPolicy ChainPolicy
+
+	// ShouldRun, if set, is called when Execute is about to execute the
+	// job. If it returns false, Run and Close are skipped entirely and
+	// the job counts as neither failed nor completed: a chain moves on to
+	// Next, and the job is not rolled back. JobGroup, Race and Start
+	// reject a job that sets it.
+	ShouldRun func() bool
+
+	// StartupRetry, if set, retries Run when it fails soon after being
+	// launched, before it is considered started. See StartupRetry.
+	StartupRetry StartupRetry
+
+	// Rollback, if set, undoes the work of a job in a Next chain that
+	// completed when a job after it fails, stopping the chain. Completed
+	// jobs are rolled back from the last to the first before the chain
+	// returns, and their Rollback errors are joined into its error.
+	// Under ContinueOnError the chain does not stop, so nothing is
+	// rolled back.
+	Rollback func() error
+
+	// DependsOn lists the jobs in the same JobGroup that must be ready,
+	// per Ready, before this job is started. The group closes this job
+	// before any of them. It is only used by JobGroup.
+	DependsOn []*Job
+
+	// mu guards the references to job comm channels.
+	mu sync.Mutex
+
 	// references to job comm channels
 	sig *chan int
 	ack *chan int
 	err *chan error
+
+	// l is the lifecycle started by the latest RunWithClose.
+	l *lifecycle
+
+	// closers are the functions added with AddCloser.
+	closers []func() error
+
+	// state holds the current State.
+	state atomic.Int32
+
+	// stateChanges is the channel returned by StateChanges, if any.
+	stateChanges chan State
+
+	// events is the channel returned by Events, if any.
+	events chan Event
+
+	// history holds the errors kept for RecentErrors.
+	history []TimestampedError
+
+	// recent holds the latest events, for DebugHandler.
+	recent []Event
+
+	// clk is set by WithClock.
+	clk Clock
+
+	// resumed is set while the job is paused, and closed by Resume.
+	resumed chan struct{}
+
+	// leak is set by WithLeakWarning.
+	leak *leakSentinel
+
+	// id is generated by ID.
+	id string
 }
 
 // RunWithClose executes the function defined in Job.Run as a
@@ -78,69 +407,990 @@ type Job struct {
 // communication. Once signaled on the "sig" channel, the function
 // defined in Job.Close will be called. Once Job.Close has finished,
 // the caller is sent a final message on the "ack" channel.
-// All errors are reported through the "err" channel.
+// If Job.Run returns on its own, with or without an error, Job.Close
+// is called without waiting for a signal.
+// All errors are reported through the "err" channel, which is buffered
+// to hold both a Run and a Close error so the job never blocks on it;
+// see Job.ErrBuffer.
+// They are wrapped in a RunError or a CloseError according to the side
+// that reported them.
+//
+// A job can only be running once at a time. If RunWithClose is called
+// while the job is still running, no new Run is launched and
+// ErrAlreadyStarted is sent on the returned "err" channel instead.
 func (j *Job) RunWithClose() (sig, ack chan int, err chan error) {
+	sig, ack, err, e := j.start(runOptions{})
+	if e != nil {
+		sig, ack, err = make(chan int, 1), make(chan int, 1), make(chan error, 1)
+		err <- e
+	}
+	return
+}
+
+// RunWithContext is like RunWithClose, but also returns the run context:
+// the context RunCtx receives, cancelled exactly when the close path
+// begins. Goroutines the caller starts alongside Run can watch it to
+// stop in step with the job. If the job is already running, the context
+// is returned already cancelled, along with ErrAlreadyStarted on "err".
+func (j *Job) RunWithContext() (ctx context.Context, sig, ack chan int, err chan error) {
+	sig, ack, err, e := j.start(runOptions{})
+	if e != nil {
+		sig, ack, err = make(chan int, 1), make(chan int, 1), make(chan error, 1)
+		err <- e
+		c, cancel := context.WithCancel(context.Background())
+		cancel()
+		return c, sig, ack, err
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.l.ctx, sig, ack, err
+}
+
+// start implements RunWithClose, returning ErrAlreadyStarted rather than
+// launching a second Run while the job is running. opts apply to this
+// run only.
+func (j *Job) start(opts runOptions) (sig, ack chan int, err chan error, e error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.l != nil && !j.l.finished() {
+		return nil, nil, nil, ErrAlreadyStarted
+	}
+
 	sig = make(chan int, 1)
-	ack = make(chan int, 1)
-	err = make(chan error, 1)
+	ack = make(chan int, j.ackBuffer())
+	n := j.ErrBuffer
+	if n < minErrBuffer {
+		n = minErrBuffer
+	}
+	err = make(chan error, n)
 
 	j.sig = &sig
 	j.ack = &ack
 	j.err = &err
 
+	j.markExecuted()
+	l := newLifecycle()
+	l.ctx.Context = j.withJob(l.ctx.Context)
+	l.opts = opts
+	l.started = j.clock().Now()
+	j.l = l
+	// State is Running once start returns; the change is reported from
+	// the goroutine, as j.mu is held here.
+	j.state.Store(int32(StateRunning))
+
 	go func() {
+		j.notifyState(StateRunning)
+		j.publish(Event{Kind: EventStarted})
 		go func() {
-			if e := j.Run(); e != nil {
-				err <- e
+			defer close(l.runDone)
+			if l.opts.run != nil || j.RunReady == nil {
+				l.markReady()
+			}
+			if e := j.runAttempts(l); e != nil && !l.serverClosed(e) {
+				j.logger().Printf("run failed: %v", e)
+				if j.SkipCloseOnPanic && errors.Is(e, errPanic) {
+					l.skipClose.Store(true)
+				}
+				re := &RunError{Err: e}
+				l.record(re)
+				j.remember(re)
+				j.publish(Event{Kind: EventErrored, Err: re})
+				j.report(l, err, re)
 			}
 		}()
-		<-sig
-		if e := j.Close(); e != nil {
-			err <- e
+		if j.OnStart != nil {
+			j.OnStart()
+		}
+		select {
+		case <-sig:
+		case <-l.runDone:
+			if l.err() != nil {
+				l.setReason(CloseRunFailed)
+			} else {
+				l.setReason(CloseRunReturned)
+			}
+		}
+		if l.skipClose.Load() {
+			j.logger().Printf("run panicked, skipping close")
+			j.setState(StateFailed)
+			l.stopRun(time.Time{})
+			j.finally()
+			l.ackPhases()
+			j.publish(Event{Kind: EventClosed})
+			ack <- 1
+			close(l.done)
+			return
 		}
+		j.setState(StateClosing)
+		j.publish(Event{Kind: EventClosing})
+		closing := j.clock().Now()
+		var grace time.Time
+		if j.CloseTimeout > 0 {
+			grace = closing.Add(j.CloseTimeout)
+		}
+		l.stopRun(grace)
+		j.logger().Printf("closing")
+		e := j.shutdown(l)
+		j.metrics().ObserveCloseDuration(j.since(closing))
+		if e != nil {
+			j.logger().Printf("close failed: %v", e)
+			ce := &CloseError{Err: e}
+			l.record(ce)
+			j.remember(ce)
+			j.publish(Event{Kind: EventErrored, Err: ce})
+			j.report(l, err, ce)
+		}
+		if errors.Is(e, ErrCloseTimeout) {
+			j.setState(StateFailed)
+			j.finally()
+			l.ackPhases()
+			j.publish(Event{Kind: EventClosed})
+			close(l.done)
+			return
+		}
+		j.logger().Printf("closed")
+		if j.OnStop != nil {
+			j.OnStop()
+		}
+		j.finally()
+		l.ackPhases()
+		if l.err() != nil {
+			j.setState(StateFailed)
+		} else {
+			j.setState(StateClosed)
+		}
+		j.publish(Event{Kind: EventClosed})
 		ack <- 1
+		close(l.done)
 	}()
-	return
+	return sig, ack, err, nil
+}
+
+// ackBuffer returns the capacity of the ack channels, per Job.AckBuffer.
+func (j *Job) ackBuffer() int {
+	if j.AckBuffer < 1 {
+		return 1
+	}
+	return j.AckBuffer
+}
+
+// minErrBuffer is the smallest capacity of the "err" channel: one Run
+// error and one Close error.
+const minErrBuffer = 2
+
+// run calls whichever run variant is set on the job, falling back to
+// Job.Run, handing ctx to those taking a context. A run function given
+// in the lifecycle's options comes first.
+func (j *Job) run(ctx context.Context, l *lifecycle) error {
+	switch {
+	case l.opts.run != nil:
+		return l.opts.run()
+	case l.opts.workers > 0:
+		return j.runWorkers(ctx, l)
+	case j.RunWithCleanup != nil:
+		return j.RunWithCleanup(l.cleanups.push)
+	case j.RunCtx != nil:
+		return j.RunCtx(ctx)
+	case j.RunWithStop != nil:
+		return j.RunWithStop(ctx, l.stop)
+	case j.RunReady != nil:
+		return j.RunReady(l.markReady)
+	case j.RunControlled != nil:
+		return j.RunControlled(&Control{job: j, ctx: ctx})
+	}
+	return j.Run()
+}
+
+// runAttempts calls the run function, calling it again after a failure
+// during startup as Job.StartupRetry allows, and after any other failure
+// for as long as the lifecycle's restart policy allows. Registered
+// cleanups are unwound after each failed attempt.
+//
+// Each attempt gets a context of its own, derived from the run context
+// and cancelled as soon as the attempt returns, so a retry never starts
+// with a context an earlier attempt had cancelled, and nothing an
+// attempt started under its context outlives it.
+func (j *Job) runAttempts(l *lifecycle) error {
+	startup, retries := j.StartupRetry.Attempts > 1, 0
+	for attempt := 1; ; attempt++ {
+		started := j.clock().Now()
+		e := recovered("Run", func() error {
+			ctx, cancel := context.WithCancel(l.ctx)
+			defer cancel()
+			return j.run(ctx, l)
+		})
+		j.metrics().ObserveRunDuration(j.since(started))
+		if e == nil {
+			return nil
+		}
+		if ce := l.cleanups.unwind(); ce != nil {
+			e = fmt.Errorf("%w (cleanup: %v)", e, ce)
+		}
+		if startup {
+			retries++
+			startup = j.since(started) < j.StartupRetry.grace() && retries < j.StartupRetry.Attempts
+			if startup && j.StartupRetry.wait(l.ctx, j.clock(), retries) {
+				j.logger().Printf("retrying startup after run failed: %v", e)
+				// A startup retry is not a restart.
+				attempt--
+				continue
+			}
+			startup = false
+		}
+		if l.opts.restart == nil || !l.opts.restart(l.ctx, attempt, e) {
+			return e
+		}
+		j.logger().Printf("restarting after run failed: %v", e)
+		j.metrics().IncRestart()
+		l.restarted(e)
+		j.remember(&RunError{Err: e})
+	}
+}
+
+// shutdown runs the close side of the lifecycle, Job.Drain, then
+// Job.Close, then any registered cleanups, then waits for the run
+// goroutine to return.
+// All of it is bounded by Job.CloseTimeout.
+func (j *Job) shutdown(l *lifecycle) error {
+	j.mu.Lock()
+	ctx := j.withJob(context.Background())
+	j.mu.Unlock()
+	if j.CloseTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = j.withTimeout(ctx, j.CloseTimeout)
+		defer cancel()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		var de error
+		if j.Drain != nil {
+			de = recovered("Drain", func() error {
+				return j.Drain(ctx)
+			})
+		}
+		l.completed(PhaseDrain, de)
+		if l.opts.workers > 0 {
+			// Workers share the resources Close releases, so
+			// let them all stop first.
+			select {
+			case <-l.runDone:
+			case <-ctx.Done():
+			}
+		}
+		e := l.closeOnce(j.StrictClose, func() error {
+			return j.closeAll(ctx, l.signal(), l.closeReason())
+		})
+		if ce := l.cleanups.unwind(); ce != nil && e == nil {
+			e = ce
+		}
+		l.completed(PhaseClose, e)
+		if de != nil {
+			e = errors.Join(de, e)
+		}
+		done <- e
+	}()
+
+	var e error
+	select {
+	case e = <-done:
+	case <-ctx.Done():
+		return j.closeTimedOut()
+	}
+	select {
+	case <-l.runDone:
+		return e
+	case <-ctx.Done():
+		return j.closeTimedOut()
+	}
+}
+
+// closeTimedOut returns ErrCloseTimeout, joined with a DrainError if
+// Job.Backlog reports items left undrained.
+func (j *Job) closeTimedOut() error {
+	if j.Backlog != nil {
+		if n := j.Backlog(); n > 0 {
+			return errors.Join(ErrCloseTimeout, &DrainError{Remaining: n})
+		}
+	}
+	return ErrCloseTimeout
+}
+
+// errPanic is wrapped by the errors recovered returns for a panic.
+var errPanic = errors.New("panic")
+
+// finally calls Job.Finally, if set, logging rather than propagating a
+// panic from it.
+func (j *Job) finally() {
+	if j.Finally == nil {
+		return
+	}
+	e := recovered("Finally", func() error {
+		j.Finally()
+		return nil
+	})
+	if e != nil {
+		j.logger().Printf("finally failed: %v", e)
+	}
+}
+
+// recovered calls fn, converting a panic into an error that names
+// the phase and carries the stack trace.
+func recovered(phase string, fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w in %s: %v\n%s", errPanic, phase, r, debug.Stack())
+		}
+	}()
+	return fn()
+}
+
+// close calls Job.CloseCtx if set, then Job.CloseSignal with s, then
+// Job.CloseWithReason with r, falling back to Job.Close. A job without
+// any has nothing to close.
+func (j *Job) close(ctx context.Context, s os.Signal, r CloseReason) error {
+	switch {
+	case j.CloseCtx != nil:
+		return j.CloseCtx(ctx)
+	case j.CloseSignal != nil:
+		return j.CloseSignal(s)
+	case j.CloseWithReason != nil:
+		return j.CloseWithReason(r)
+	case j.Close != nil:
+		return j.Close()
+	}
+	return nil
+}
+
+// AddCloser registers fn to run on the close path, after Close. Closers
+// run in LIFO order, like deferred calls, and all of them run even if
+// one fails; their errors are returned joined with that of Close. They
+// stay registered for every later run of the job, and with closers
+// registered Close itself is optional.
+func (j *Job) AddCloser(fn func() error) {
+	j.mu.Lock()
+	j.closers = append(j.closers, fn)
+	j.mu.Unlock()
+}
+
+// closeAll calls the job's close function, passing s to CloseSignal and
+// r to CloseWithReason, and then the closers added
+// with AddCloser, recovering from panics in any of them.
+func (j *Job) closeAll(ctx context.Context, s os.Signal, r CloseReason) error {
+	e := recovered("Close", func() error {
+		return j.close(ctx, s, r)
+	})
+
+	j.mu.Lock()
+	closers := j.closers
+	j.mu.Unlock()
+	if len(closers) == 0 {
+		return e
+	}
+
+	errs := []error{e}
+	for i := len(closers) - 1; i >= 0; i-- {
+		errs = append(errs, recovered("closer", closers[i]))
+	}
+	return errors.Join(errs...)
 }
 
 // Execute is a blocking method that calls RunWithClose and
 // sets up a channel to listen for signals defined in Job.Signals.
-// Will return error if RunWithClose results in an error from either
-// Job.Run or Job.Close.
+// It returns once the close path has finished. Errors from Job.Run and
+// Job.Close are both collected and returned joined with errors.Join;
+// Job.Close still runs when Job.Run has failed.
+// A second signal received while the job is closing makes Execute return
+// ErrForcedShutdown at once, without waiting for Job.Close to finish.
+// While a close is deferred by Job.MinUptime, a signal instead starts
+// the close path at once; a signal after that forces shutdown, and if
+// Close times out Execute returns ErrForcedShutdown as well.
+//
+// Close triggers coalesce: however many signals, SignalToClose calls,
+// context cancellations and other triggers arrive, the job is closed
+// once. Only signals count towards forcing shutdown, whatever started
+// the close; the other triggers are ignored once the close has begun.
+// If Job.Next is set, the next job is executed once this one has
+// closed cleanly; see Job.Next.
+//
+// Jobs executing at the same time share a single registration of each
+// signal with package signal, and every one of them notified on a
+// signal receives it: one SIGINT closes them all. The registration is
+// released once the last of them returns. Use a JobGroup to coordinate
+// how such jobs close.
 func (j *Job) Execute() error {
+	return j.executeChain(nil, runOptions{})
+}
 
-	// sanity check for job, requires both Run and Close functions defined.
-	if j.Run == nil || j.Close == nil {
-		return fmt.Errorf("either Run or Close fields missing")
+// ExecuteContext is like Execute, but also closes the job when ctx is
+// done, just as a signal would, and then waits for the close to finish.
+// A chain of jobs stops after the job that was running when ctx was done.
+func (j *Job) ExecuteContext(ctx context.Context) error {
+	return j.executeChain(ctx.Done(), runOptions{})
+}
+
+// ExecuteWithCancel calls Execute without blocking. The returned done
+// channel delivers the result of Execute once it returns. Calling cancel
+// triggers the close path just as a signal would.
+func (j *Job) ExecuteWithCancel() (done <-chan error, cancel context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() {
+		result <- j.ExecuteContext(ctx)
+		cancel()
+	}()
+	return result, cancel
+}
+
+// execute runs a single job for Execute, ignoring Job.Next. In addition
+// to signals, a receive on trigger starts the close path; a nil trigger
+// is never ready. opts are passed on to start. signaled reports
+// whether a signal started the close path, so that the chain does not
+// carry on past it.
+func (j *Job) execute(trigger <-chan struct{}, opts runOptions) (signaled bool, result error) {
+
+	if e := j.validate(opts); e != nil {
+		return false, e
+	}
+
+	if j.TelemetryFlush != nil {
+		defer func() {
+			if e := j.flushTelemetry(); e != nil && result == nil {
+				result = e
+			}
+		}()
 	}
 
-	sig, ack, err := j.RunWithClose()
+	opts.executed = true
+	sig, ack, err, e := j.start(opts)
+	if e != nil {
+		return false, e
+	}
+	started := j.clock().Now()
+	j.mu.Lock()
+	l := j.l
+	j.mu.Unlock()
+	defer func() {
+		close(l.executed)
+	}()
 
-	closeChan := make(chan os.Signal, 1)
-	if len(j.Signals) == 0 {
-		j.Signals = []os.Signal{
-			syscall.SIGINT,
-			syscall.SIGTERM,
+	closeChan := make(chan os.Signal, closeSignals)
+	notifySignals(closeChan, j.signals()...)
+	defer stopSignals(closeChan)
+
+	var handlerChan chan os.Signal
+	if len(j.SignalHandlers) > 0 {
+		handlerChan = make(chan os.Signal, 1)
+		for s := range j.SignalHandlers {
+			notifySignals(handlerChan, s)
+		}
+		defer stopSignals(handlerChan)
+	}
+
+	var reloadChan chan os.Signal
+	if len(j.ReloadSignals) > 0 {
+		reloadChan = make(chan os.Signal, 1)
+		notifySignals(reloadChan, j.ReloadSignals...)
+		defer stopSignals(reloadChan)
+	}
+
+	var deadline <-chan time.Time
+	if j.MaxRuntime > 0 {
+		t := j.clock().NewTimer(j.MaxRuntime)
+		defer t.Stop()
+		deadline = t.C()
+	}
+
+	// startup fires if the run is not ready within StartupTimeout. It
+	// is armed again for the run a restart launches.
+	var (
+		startupTimer Timer
+		startup      <-chan time.Time
+		ready        <-chan struct{}
+	)
+	armStartup := func() {
+		if j.StartupTimeout <= 0 {
+			return
+		}
+		if startupTimer != nil {
+			startupTimer.Stop()
+		}
+		startupTimer = j.clock().NewTimer(j.StartupTimeout)
+		startup = startupTimer.C()
+		ready = l.ready
+	}
+	armStartup()
+	defer func() {
+		if startupTimer != nil {
+			startupTimer.Stop()
+		}
+	}()
+
+	var diskCheck <-chan time.Time
+	if j.MinFreeDiskBytes > 0 {
+		t := time.NewTicker(j.diskCheckInterval())
+		defer t.Stop()
+		diskCheck = t.C
+	}
+
+	// errs collects errors from both the run and close phases.
+	var errs []error
+
+	// received counts signals; a second one forces shutdown once the
+	// close path has begun. escalated is set when a signal cut a
+	// MinUptime deferral short instead.
+	received := 0
+	escalated := false
+
+	// closeRequested is set once anything other than Restart has
+	// asked the job to close, so it is not restarted.
+	closeRequested := false
+
+	// deferred fires once MinUptime is reached if a close
+	// trigger arrived too early.
+	var deferred <-chan time.Time
+	requestClose := func(r CloseReason) {
+		closeRequested = true
+		l.setReason(r)
+		if remaining := j.MinUptime - j.since(started); remaining > 0 {
+			if deferred == nil {
+				deferred = j.clock().After(remaining)
+				l.setDeferred(j.clock().Now().Add(remaining))
+				if j.OnClosingSoon != nil {
+					j.OnClosingSoon(remaining)
+				}
+			}
+			return
 		}
+		signalClose(sig)
 	}
-	signal.Notify(closeChan, j.Signals...)
 
 LOOP:
 	for {
 		select {
-		case <-closeChan:
-			sig <- 1
+		case s := <-closeChan:
+			signaled = true
+			received++
+			if j.OnSignal != nil {
+				j.OnSignal(s)
+			}
+			if deferred != nil {
+				j.logger().Printf("received signal %v while close is deferred, closing now", s)
+				j.publish(Event{Kind: EventSignalReceived, Signal: s})
+				l.setSignal(s)
+				l.setReason(CloseSignaled)
+				deferred = nil
+				l.setDeferred(time.Time{})
+				escalated = true
+				signalClose(sig)
+				continue
+			}
+			if received > 1 {
+				j.logger().Printf("received signal %v again, forcing shutdown", s)
+				return true, errors.Join(append([]error{ErrForcedShutdown, result}, errs...)...)
+			}
+			j.logger().Printf("received signal %v", s)
+			j.publish(Event{Kind: EventSignalReceived, Signal: s})
+			l.setSignal(s)
+			requestClose(CloseSignaled)
+		case s := <-handlerChan:
+			j.logger().Printf("received signal %v, calling its handler", s)
+			j.SignalHandlers[s]()
+		case s := <-reloadChan:
+			j.logger().Printf("received signal %v, reloading", s)
+			if e := recovered("Reload", j.Reload); e != nil {
+				j.logger().Printf("reload failed: %v", e)
+				errs = append(errs, e)
+				if errors.Is(e, ErrReloadFatal) {
+					requestClose(CloseReloadFailed)
+				}
+			}
+		case <-trigger:
+			trigger = nil
+			requestClose(CloseCanceled)
+		case <-deferred:
+			deferred = nil
+			l.setDeferred(time.Time{})
+			signalClose(sig)
+		case <-deadline:
+			j.logger().Printf("max runtime of %v exceeded", j.MaxRuntime)
+			deadline = nil
+			result = ErrDeadlineExceeded
+			closeRequested = true
+			l.setReason(CloseDeadline)
+			signalClose(sig)
+		case <-ready:
+			ready = nil
+			startup = nil
+		case <-startup:
+			j.logger().Printf("not ready after startup timeout of %v", j.StartupTimeout)
+			startup = nil
+			ready = nil
+			result = ErrStartupTimeout
+			closeRequested = true
+			l.setReason(CloseStartupTimeout)
+			signalClose(sig)
+		case <-diskCheck:
+			if j.lowDisk() {
+				j.logger().Printf("free disk space on %s below %d bytes", j.WatchPath, j.MinFreeDiskBytes)
+				diskCheck = nil
+				result = ErrLowDisk
+				requestClose(CloseLowDisk)
+			}
 		case <-ack:
-			break LOOP
+			// Run and Close errors are sent before ack, so they may
+			// still be pending when ack is received.
+			for pending := true; pending; {
+				select {
+				case e := <-err:
+					errs = append(errs, e)
+				default:
+					pending = false
+				}
+			}
+			restart := l.restartRequested()
+			if restart == nil || closeRequested {
+				break LOOP
+			}
+
+			// Job.Restart has closed the job to run it again. The
+			// errors of the closed run are returned from Restart.
+			<-l.done
+			old := l
+			sig, ack, err, e = j.start(opts)
+			restart <- e
+			if e != nil {
+				errs = append(errs, e)
+				break LOOP
+			}
+			errs = nil
+			started = j.clock().Now()
+			j.mu.Lock()
+			l = j.l
+			j.mu.Unlock()
+			armStartup()
+			close(old.executed)
 		case e := <-err:
+			errs = append(errs, e)
+			if errors.Is(e, ErrCloseTimeout) {
+				// Close never finished, so no ack will follow. Finally
+				// is still to run, and TelemetryFlush must follow it.
+				<-l.done
+				if escalated {
+					errs = append([]error{ErrForcedShutdown}, errs...)
+				}
+				break LOOP
+			}
+		}
+	}
+
+	return signaled, errors.Join(append([]error{result}, errs...)...)
+}
+
+// SignalToClose signals a started job to close. It returns ErrNotStarted
+// if RunWithClose has not been called yet. It is safe to call more than
+// once; a close that is already pending is not signaled again.
+func (j *Job) SignalToClose() error {
+	j.mu.Lock()
+	sig := j.sig
+	j.mu.Unlock()
+
+	if sig == nil {
+		return ErrNotStarted
+	}
+	signalClose(*sig)
+	return nil
+}
+
+// report sends e on err. The channel is sized to hold every error a run
+// reports, so the send only has to wait if something else has filled it.
+// For a run driven by Execute, which reads err until the close path has
+// finished, it then waits until Execute has moved on from l, so the
+// reporting goroutine exits once nobody is left to read e. Otherwise
+// nothing says when the caller of RunWithClose is done with err, and e
+// is dropped at once. Either way, a dropped error is logged.
+func (j *Job) report(l *lifecycle, err chan error, e error) {
+	select {
+	case err <- e:
+		return
+	default:
+	}
+	if l.opts.executed {
+		select {
+		case err <- e:
+			return
+		case <-l.executed:
+		}
+	}
+	j.logger().Printf("err channel full, dropping: %v", e)
+}
+
+// closeSignals is the capacity of the channel Execute receives its
+// signals on. Only the first two signals matter, the one that closes the
+// job and the one that forces shutdown, so both are kept even if they
+// arrive before Execute has read either.
+const closeSignals = 2
+
+// signalClose sends on sig without blocking. If a close is already
+// pending, or the close path has already taken its trigger, there is
+// nothing more to signal.
+func signalClose(sig chan int) {
+	select {
+	case sig <- 1:
+	default:
+	}
+}
+
+// signals returns the signals Execute notifies on: Job.Signals, or the
+// defaults if none are set. Job.Signals itself is left untouched.
+func (j *Job) signals() []os.Signal {
+	if len(j.Signals) == 0 {
+		return defaultSignals
+	}
+	return j.Signals
+}
+
+// flushTelemetry calls Job.TelemetryFlush under its deadline.
+func (j *Job) flushTelemetry() error {
+	timeout := j.TelemetryFlushTimeout
+	if timeout <= 0 {
+		timeout = defaultTelemetryFlushTimeout
+	}
+	j.mu.Lock()
+	ctx := j.withJob(context.Background())
+	j.mu.Unlock()
+	ctx, cancel := j.withTimeout(ctx, timeout)
+	defer cancel()
+	return j.TelemetryFlush(ctx)
+}
+
+// Validate reports whether the job is configured well enough to Execute.
+// It requires both Run and Close to be defined, or RunWithCleanup in their
+// place, with closers added by AddCloser standing in for Close. It
+// rejects signals that cannot be caught.
+func (j *Job) Validate() error {
+	return j.validate(runOptions{})
+}
+
+// validate implements Validate, counting a run function given in opts
+// as the job's Run.
+func (j *Job) validate(opts runOptions) error {
+	// sanity check for job, requires both Run and Close functions defined.
+	// RunWithCleanup may stand in for both, and the Ctx variants for either.
+	hasRun := opts.run != nil || j.Run != nil || j.RunCtx != nil || j.RunWithStop != nil || j.RunReady != nil || j.RunControlled != nil
+	j.mu.Lock()
+	hasClose := j.Close != nil || j.CloseCtx != nil || j.CloseSignal != nil || j.CloseWithReason != nil || len(j.closers) > 0
+	j.mu.Unlock()
+	if j.RunWithCleanup == nil && (!hasRun || !hasClose) {
+		return fmt.Errorf("either Run or Close fields missing")
+	}
+
+	if j.MinFreeDiskBytes > 0 && j.WatchPath == "" {
+		return fmt.Errorf("WatchPath required with MinFreeDiskBytes")
+	}
+
+	for _, s := range j.Signals {
+		if e := catchable(s); e != nil {
+			return e
+		}
+	}
+
+	for h := range j.SignalHandlers {
+		if e := catchable(h); e != nil {
+			return e
+		}
+		if hasSignal(j.signals(), h) {
+			return fmt.Errorf("signal %v both closes the job and has a handler", h)
+		}
+	}
+
+	if len(j.ReloadSignals) > 0 && j.Reload == nil {
+		return fmt.Errorf("Reload required with ReloadSignals")
+	}
+	for _, r := range j.ReloadSignals {
+		if e := catchable(r); e != nil {
 			return e
 		}
+		if hasSignal(j.signals(), r) {
+			return fmt.Errorf("signal %v both closes and reloads the job", r)
+		}
+		if _, ok := j.SignalHandlers[r]; ok {
+			return fmt.Errorf("signal %v both reloads the job and has a handler", r)
+		}
 	}
+	return nil
+}
 
+// catchable returns an error if s cannot be caught.
+func catchable(s os.Signal) error {
+	for _, u := range uncatchableSignals {
+		if s == u {
+			return fmt.Errorf("signal %v cannot be caught", s)
+		}
+	}
 	return nil
 }
 
-// Helper function to signal a job to close.
-func (j *Job) SignalToClose() {
-	*j.sig <- 1
+// hasSignal reports whether signals contains s.
+func hasSignal(signals []os.Signal, s os.Signal) bool {
+	for _, t := range signals {
+		if t == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Done returns a channel that is closed once the run and close paths
+// started by RunWithClose have both returned, or the close path has
+// timed out per Job.CloseTimeout.
+// It returns nil if the job has not been started.
+func (j *Job) Done() <-chan struct{} {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.l == nil {
+		return nil
+	}
+	return j.l.done
+}
+
+// Ready returns a channel that is closed once the job started by the
+// latest RunWithClose is ready, as described on Job.RunReady. It returns
+// nil if the job has not been started.
+func (j *Job) Ready() <-chan struct{} {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.l == nil {
+		return nil
+	}
+	return j.l.ready
+}
+
+// Wait blocks until Done is closed and returns the errors reported by
+// Job.Run and Job.Close, joined. It returns ErrNotStarted if the job has
+// not been started. Wait does not consume the channels returned by
+// RunWithClose.
+func (j *Job) Wait() error {
+	j.mu.Lock()
+	l := j.l
+	j.mu.Unlock()
+	if l == nil {
+		return ErrNotStarted
+	}
+	<-l.done
+	return l.err()
+}
+
+// Start launches the job as RunWithClose does, for callers that manage
+// its lifecycle themselves: no signals are notified on and Job.Next is
+// not followed. It returns once the job is ready, as described on
+// Job.RunReady. If Run returns without having become ready, Start waits
+// for the job to close and returns its errors. Call Stop to close the
+// job.
+func (j *Job) Start() error {
+	if j.ShouldRun != nil {
+		return fmt.Errorf("ShouldRun not supported by Start")
+	}
+	if e := j.validate(runOptions{}); e != nil {
+		return e
+	}
+	_, _, _, e := j.start(runOptions{})
+	if e != nil {
+		return e
+	}
+
+	j.mu.Lock()
+	l := j.l
+	j.mu.Unlock()
+	select {
+	case <-l.ready:
+		return nil
+	case <-l.runDone:
+	}
+	select {
+	case <-l.ready:
+		// Run became ready before it returned.
+		return nil
+	default:
+	}
+	return j.Wait()
+}
+
+// Stop closes a job launched with Start and waits for the close to
+// finish, returning the errors reported by Job.Run and Job.Close joined,
+// as Wait does. If ctx is done first, Stop returns its error and the
+// close carries on in the background.
+func (j *Job) Stop(ctx context.Context) error {
+	if e := j.SignalToClose(); e != nil {
+		return e
+	}
+	select {
+	case <-j.Done():
+		return j.Wait()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Restart closes the running job and then launches it again, for
+// instance after a change of configuration. It waits for the close to
+// finish and returns its errors along with any error starting the job
+// again. A job run by Execute carries on under the same Execute, with
+// its signal handling in place; otherwise the job is relaunched as by
+// RunWithClose, and the channels returned for the closed run are done
+// with. The job is not relaunched if its Close timed out, or if it was
+// asked to close for another reason meanwhile.
+// It returns ErrNotStarted if the job is not running. A member of a
+// running JobGroup or Race cannot be restarted.
+func (j *Job) Restart() error {
+	j.mu.Lock()
+	l := j.l
+	var sig chan int
+	if j.sig != nil {
+		sig = *j.sig
+	}
+	j.mu.Unlock()
+	if l == nil || j.State() != StateRunning {
+		return ErrNotStarted
+	}
+	if l.opts.member {
+		return errors.New("Restart not supported for a member of a JobGroup or Race")
+	}
+	restarted, ok := l.requestRestart()
+	if !ok {
+		return errors.New("job already restarting")
+	}
+
+	signalClose(sig)
+	<-l.done
+	e := l.err()
+	if errors.Is(e, ErrCloseTimeout) {
+		return e
+	}
+	if !l.opts.executed {
+		_, _, _, se := j.start(l.opts)
+		return errors.Join(e, se)
+	}
+	select {
+	case se := <-restarted:
+		return errors.Join(e, se)
+	case <-l.executed:
+	}
+	select {
+	case se := <-restarted:
+		return errors.Join(e, se)
+	default:
+		return errors.Join(e, errors.New("job closed instead of restarting"))
+	}
+}
+
+// ExecuteAsync calls Execute in a goroutine and returns immediately.
+// Once Execute returns, done is called exactly once with its result.
+func (j *Job) ExecuteAsync(done func(error)) {
+	go func() {
+		err := j.Execute()
+		if done != nil {
+			done(err)
+		}
+	}()
 }
@@ -1,16 +1,24 @@
 package async_test
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
 	"syscall"
 	"testing"
 	"time"
 
 	"github.com/jharshman/async"
+	"github.com/jharshman/async/asynctest"
 )
 
 func Test_RunWithClose(t *testing.T) {
@@ -59,6 +67,69 @@ LOOP:
 	}
 }
 
+func TestJob_RunWithContext(t *testing.T) {
+	stopped := make(chan struct{})
+	var ctx context.Context
+	job := async.Job{
+		Run: func() error {
+			<-stopped
+			return nil
+		},
+		Close: func() error {
+			select {
+			case <-ctx.Done():
+			default:
+				t.Error("expected the context to be cancelled before Close")
+			}
+			close(stopped)
+			return nil
+		},
+	}
+
+	ctx, sig, ack, _ := job.RunWithContext()
+	if ctx.Err() != nil {
+		t.Error("expected the context to be live while the job runs")
+	}
+
+	// error expected here
+	again, _, _, err := job.RunWithContext()
+	if again.Err() == nil {
+		t.Error("expected a cancelled context for a job already started")
+	}
+	if e := <-err; e != async.ErrAlreadyStarted {
+		t.Errorf("expected %v, got %v", async.ErrAlreadyStarted, e)
+	}
+
+	sig <- 1
+	<-ack
+}
+
+func TestJob_RunWithCloseUnreadErrors(t *testing.T) {
+	before := runtime.NumGoroutine()
+	for i := 0; i < 10; i++ {
+		job := async.Job{
+			Run: func() error {
+				return errors.New("run failed")
+			},
+			Close: func() error {
+				return errors.New("close failed")
+			},
+		}
+		// err is never read
+		_, ack, _ := job.RunWithClose()
+		<-ack
+		<-job.Done()
+	}
+
+	deadline := time.Now().Add(time.Second * 2)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		<-time.After(time.Millisecond * 10)
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("expected no goroutines left behind, %d before and %d after", before, after)
+	}
+}
+
 func TestJob_Execute(t *testing.T) {
 	s := http.Server{
 		Addr:    ":8080",
@@ -140,6 +211,32 @@ func TestJob_ExecuteCloseWithErrors(t *testing.T) {
 	}
 }
 
+func TestJob_ErrBuffer(t *testing.T) {
+	for _, tc := range []struct {
+		buffer, want int
+	}{
+		{0, 2},
+		{1, 2},
+		{8, 8},
+	} {
+		job := async.Job{
+			Run: func() error {
+				return nil
+			},
+			Close: func() error {
+				return nil
+			},
+			ErrBuffer: tc.buffer,
+		}
+
+		_, ack, err := job.RunWithClose()
+		if got := cap(err); got != tc.want {
+			t.Errorf("ErrBuffer %d: expected capacity %d, got %d", tc.buffer, tc.want, got)
+		}
+		<-ack
+	}
+}
+
 func TestJob_ExecuteNoCloseDefined(t *testing.T) {
 	job := async.Job{
 		Run: func() error {
@@ -192,3 +289,1659 @@ func TestJob_SignalToClose(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestJob_RunWithCleanupErrors(t *testing.T) {
+	var ran []string
+	job := async.Job{
+		RunWithCleanup: func(cleanup func(fn func() error)) error {
+			cleanup(func() error {
+				ran = append(ran, "first")
+				return nil
+			})
+			cleanup(func() error {
+				ran = append(ran, "second")
+				return nil
+			})
+			return errors.New("some error")
+		},
+	}
+
+	// error expected here
+	err := job.Execute()
+	if err == nil {
+		t.Error(err)
+	}
+	if len(ran) != 2 || ran[0] != "second" || ran[1] != "first" {
+		t.Errorf("expected cleanups in LIFO order, got %v", ran)
+	}
+}
+
+func TestJob_RunWithCleanupOnClose(t *testing.T) {
+	var ran []string
+	job := async.Job{
+		RunWithCleanup: func(cleanup func(fn func() error)) error {
+			cleanup(func() error {
+				ran = append(ran, "cleanup")
+				return nil
+			})
+			return nil
+		},
+		Close: func() error {
+			ran = append(ran, "close")
+			return nil
+		},
+	}
+
+	go func() {
+		<-time.After(time.Millisecond * 100)
+		job.SignalToClose()
+	}()
+
+	err := job.Execute()
+	if err != nil {
+		t.Error(err)
+	}
+	if len(ran) != 2 || ran[0] != "close" || ran[1] != "cleanup" {
+		t.Errorf("expected Close then cleanups, got %v", ran)
+	}
+}
+
+func TestJob_StartStop(t *testing.T) {
+	ready := make(chan struct{})
+	stopped := make(chan struct{})
+	job := async.Job{
+		RunReady: func(markReady func()) error {
+			<-ready
+			markReady()
+			<-stopped
+			return nil
+		},
+		Close: func() error {
+			close(stopped)
+			return nil
+		},
+	}
+
+	go func() {
+		<-time.After(time.Millisecond * 50)
+		close(ready)
+	}()
+	if err := job.Start(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-job.Ready():
+	default:
+		t.Error("expected Start to return once the job was ready")
+	}
+	if s := job.State(); s != async.StateRunning {
+		t.Errorf("expected the job to be running, got %v", s)
+	}
+
+	if err := job.Stop(context.Background()); err != nil {
+		t.Error(err)
+	}
+	if s := job.State(); s != async.StateClosed {
+		t.Errorf("expected Stop to close the job, got %v", s)
+	}
+}
+
+func TestJob_StartRunFails(t *testing.T) {
+	errStartup := errors.New("startup failed")
+	job := async.Job{
+		RunReady: func(ready func()) error {
+			return errStartup
+		},
+		Close: func() error {
+			return nil
+		},
+	}
+
+	// error expected here
+	if err := job.Start(); !errors.Is(err, errStartup) {
+		t.Errorf("expected %v, got %v", errStartup, err)
+	}
+	// error expected here
+	if err := (&async.Job{}).Stop(context.Background()); err != async.ErrNotStarted {
+		t.Errorf("expected %v, got %v", async.ErrNotStarted, err)
+	}
+}
+
+func TestJob_StartShouldRun(t *testing.T) {
+	job := async.Job{
+		Run: func() error {
+			return nil
+		},
+		Close: func() error {
+			return nil
+		},
+		ShouldRun: func() bool {
+			return true
+		},
+	}
+
+	// error expected here
+	if err := job.Start(); err == nil || !strings.Contains(err.Error(), "ShouldRun") {
+		t.Errorf("expected ShouldRun to be rejected, got %v", err)
+	}
+}
+
+// restartable returns a job whose Run blocks until Close is called, and
+// a channel receiving each launch of Run.
+func restartable() (*async.Job, chan int) {
+	var mu sync.Mutex
+	var stop chan struct{}
+	launches := make(chan int, 4)
+	n := 0
+	job := &async.Job{
+		Run: func() error {
+			mu.Lock()
+			stop = make(chan struct{})
+			n++
+			launches <- n
+			s := stop
+			mu.Unlock()
+			<-s
+			return nil
+		},
+		Close: func() error {
+			mu.Lock()
+			defer mu.Unlock()
+			close(stop)
+			return nil
+		},
+	}
+	return job, launches
+}
+
+func TestJob_Restart(t *testing.T) {
+	job, launches := restartable()
+	if err := job.Start(); err != nil {
+		t.Fatal(err)
+	}
+	<-launches
+
+	if err := job.Restart(); err != nil {
+		t.Fatal(err)
+	}
+	if n := <-launches; n != 2 {
+		t.Errorf("expected Run to be launched again, got launch %d", n)
+	}
+	if s := job.State(); s != async.StateRunning {
+		t.Errorf("expected the job to be running, got %v", s)
+	}
+	if err := job.Stop(context.Background()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestJob_ExecuteRestart(t *testing.T) {
+	job, launches := restartable()
+	result := make(chan error)
+	go func() {
+		result <- job.Execute()
+	}()
+	<-launches
+
+	if err := job.Restart(); err != nil {
+		t.Fatal(err)
+	}
+	if n := <-launches; n != 2 {
+		t.Errorf("expected Run to be launched again, got launch %d", n)
+	}
+	select {
+	case err := <-result:
+		t.Fatalf("expected Execute to carry on after Restart, got %v", err)
+	default:
+	}
+
+	if err := job.SignalToClose(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Error(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected Execute to return once the restarted job closed")
+	}
+}
+
+func TestJob_RestartNotStarted(t *testing.T) {
+	job, _ := restartable()
+	// error expected here
+	if err := job.Restart(); err != async.ErrNotStarted {
+		t.Errorf("expected %v, got %v", async.ErrNotStarted, err)
+	}
+
+	if err := job.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if err := job.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// error expected here
+	if err := job.Restart(); err != async.ErrNotStarted {
+		t.Errorf("expected %v, got %v", async.ErrNotStarted, err)
+	}
+}
+
+func TestJob_ExecuteAsync(t *testing.T) {
+	job := async.Job{
+		Run: func() error {
+			return errors.New("some error")
+		},
+		Close: func() error {
+			return nil
+		},
+	}
+
+	result := make(chan error, 1)
+	job.ExecuteAsync(func(err error) {
+		result <- err
+	})
+
+	select {
+	case err := <-result:
+		// error expected here
+		if err == nil {
+			t.Error(err)
+		}
+	case <-time.After(time.Second * 5):
+		t.Error("timed out waiting for done")
+	}
+}
+
+func TestJob_ExecuteWithCancel(t *testing.T) {
+	closed := false
+	job := async.Job{
+		Run: func() error {
+			return nil
+		},
+		Close: func() error {
+			closed = true
+			return nil
+		},
+	}
+
+	done, cancel := job.ExecuteWithCancel()
+	<-time.After(time.Millisecond * 100)
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Error(err)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for done")
+	}
+	if !closed {
+		t.Error("expected Close to be called")
+	}
+}
+
+func TestJob_MinUptime(t *testing.T) {
+	job := async.Job{
+		RunCtx: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		Close: func() error {
+			return nil
+		},
+		MinUptime: time.Millisecond * 300,
+	}
+
+	start := time.Now()
+	done, cancel := job.ExecuteWithCancel()
+	<-time.After(time.Millisecond * 100)
+	cancel()
+
+	if err := <-done; err != nil {
+		t.Error(err)
+	}
+	if elapsed := time.Since(start); elapsed < job.MinUptime {
+		t.Errorf("expected close to be deferred until %v, closed after %v", job.MinUptime, elapsed)
+	}
+}
+
+func TestJob_ValidateUncatchableSignal(t *testing.T) {
+	job := async.Job{
+		Run: func() error {
+			return nil
+		},
+		Close: func() error {
+			return nil
+		},
+		Signals: []os.Signal{syscall.SIGINT, syscall.SIGKILL},
+	}
+
+	// error expected here
+	err := job.Validate()
+	if err == nil {
+		t.Error(err)
+	}
+}
+
+func TestJob_RunWithStop(t *testing.T) {
+	ctxDone := make(chan struct{})
+	stopDone := make(chan struct{})
+	job := async.Job{
+		RunWithStop: func(ctx context.Context, stop <-chan struct{}) error {
+			go func() {
+				<-ctx.Done()
+				close(ctxDone)
+			}()
+			<-stop
+			close(stopDone)
+			return nil
+		},
+		Close: func() error {
+			return nil
+		},
+	}
+
+	done, cancel := job.ExecuteWithCancel()
+	cancel()
+
+	if err := <-done; err != nil {
+		t.Error(err)
+	}
+	for name, c := range map[string]chan struct{}{"ctx": ctxDone, "stop": stopDone} {
+		select {
+		case <-c:
+		case <-time.After(time.Second * 5):
+			t.Errorf("expected %s to fire on close", name)
+		}
+	}
+}
+
+func TestJob_OnClosingSoon(t *testing.T) {
+	var events []string
+	var warned time.Duration
+	job := async.Job{
+		RunCtx: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		Close: func() error {
+			events = append(events, "close")
+			return nil
+		},
+		MinUptime: time.Millisecond * 300,
+		OnClosingSoon: func(in time.Duration) {
+			events = append(events, "closing soon")
+			warned = in
+		},
+	}
+
+	done, cancel := job.ExecuteWithCancel()
+	cancel()
+
+	if err := <-done; err != nil {
+		t.Error(err)
+	}
+	if len(events) != 2 || events[0] != "closing soon" || events[1] != "close" {
+		t.Errorf("expected closing soon before close, got %v", events)
+	}
+	if warned <= 0 || warned > job.MinUptime {
+		t.Errorf("expected remaining grace within MinUptime, got %v", warned)
+	}
+}
+
+func TestJob_TelemetryFlush(t *testing.T) {
+	var events []string
+	registered := make(chan struct{})
+	job := async.Job{
+		RunWithCleanup: func(cleanup func(fn func() error)) error {
+			cleanup(func() error {
+				events = append(events, "cleanup")
+				return nil
+			})
+			close(registered)
+			return nil
+		},
+		Close: func() error {
+			events = append(events, "close")
+			return nil
+		},
+		TelemetryFlush: func(ctx context.Context) error {
+			if _, ok := ctx.Deadline(); !ok {
+				t.Error("expected flush context to have a deadline")
+			}
+			events = append(events, "flush")
+			return nil
+		},
+	}
+
+	done, cancel := job.ExecuteWithCancel()
+	<-registered
+	cancel()
+
+	if err := <-done; err != nil {
+		t.Error(err)
+	}
+	if len(events) != 3 || events[2] != "flush" {
+		t.Errorf("expected flush to run last, got %v", events)
+	}
+}
+
+func TestJob_TelemetryFlushRunWithErrors(t *testing.T) {
+	flushed := false
+	job := async.Job{
+		Run: func() error {
+			return errors.New("some error")
+		},
+		Close: func() error {
+			return nil
+		},
+		TelemetryFlush: func(ctx context.Context) error {
+			flushed = true
+			return nil
+		},
+	}
+
+	// error expected here
+	err := job.Execute()
+	if err == nil {
+		t.Error(err)
+	}
+	if !flushed {
+		t.Error("expected flush to run on the error path")
+	}
+}
+
+func TestJob_TelemetryFlushCloseTimeout(t *testing.T) {
+	var mu sync.Mutex
+	var events []string
+	release := make(chan struct{})
+	defer close(release)
+	job := async.Job{
+		Run: func() error {
+			return nil
+		},
+		Close: func() error {
+			<-release
+			return nil
+		},
+		CloseTimeout: time.Millisecond * 50,
+		Finally: func() {
+			<-time.After(time.Millisecond * 100)
+			mu.Lock()
+			events = append(events, "finally")
+			mu.Unlock()
+		},
+		TelemetryFlush: func(ctx context.Context) error {
+			mu.Lock()
+			events = append(events, "flush")
+			mu.Unlock()
+			return nil
+		},
+	}
+
+	// error expected here
+	err := job.Execute()
+	if !errors.Is(err, async.ErrCloseTimeout) {
+		t.Errorf("expected ErrCloseTimeout, got %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !reflect.DeepEqual(events, []string{"finally", "flush"}) {
+		t.Errorf("expected flush to follow Finally, got %v", events)
+	}
+}
+
+func TestJob_ExecuteCtx(t *testing.T) {
+	closed := false
+	job := async.Job{
+		RunCtx: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		CloseCtx: func(ctx context.Context) error {
+			closed = true
+			return nil
+		},
+	}
+
+	go func() {
+		<-time.After(time.Millisecond * 100)
+		job.SignalToClose()
+	}()
+
+	err := job.Execute()
+	if err != nil {
+		t.Error(err)
+	}
+	if !closed {
+		t.Error("expected CloseCtx to be called")
+	}
+}
+
+func TestJob_ExecuteRunCtxCancelledBeforeClose(t *testing.T) {
+	cancelled := false
+	runCtx := make(chan context.Context, 1)
+	job := async.Job{
+		RunCtx: func(ctx context.Context) error {
+			runCtx <- ctx
+			<-ctx.Done()
+			return nil
+		},
+		Close: func() error {
+			cancelled = (<-runCtx).Err() != nil
+			return nil
+		},
+	}
+
+	go func() {
+		<-time.After(time.Millisecond * 100)
+		job.SignalToClose()
+	}()
+
+	err := job.Execute()
+	if err != nil {
+		t.Error(err)
+	}
+	if !cancelled {
+		t.Error("expected RunCtx context to be cancelled before Close")
+	}
+}
+
+func TestJob_ExecuteRunCtxDeadline(t *testing.T) {
+	var remaining time.Duration
+	job := async.Job{
+		RunCtx: func(ctx context.Context) error {
+			if _, ok := ctx.Deadline(); ok {
+				t.Error("expected no deadline before a close trigger")
+			}
+			<-ctx.Done()
+			if d, ok := ctx.Deadline(); ok {
+				remaining = time.Until(d)
+			}
+			return nil
+		},
+		Close: func() error {
+			return nil
+		},
+		CloseTimeout: time.Second * 2,
+	}
+
+	go func() {
+		<-time.After(time.Millisecond * 100)
+		job.SignalToClose()
+	}()
+
+	err := job.Execute()
+	if err != nil {
+		t.Error(err)
+	}
+	if remaining <= 0 || remaining > time.Second*2 {
+		t.Errorf("expected a deadline within CloseTimeout, got %v remaining", remaining)
+	}
+}
+
+func TestJob_ExecuteCloseTimeout(t *testing.T) {
+	job := async.Job{
+		Run: func() error {
+			return nil
+		},
+		Close: func() error {
+			<-time.After(time.Second * 5)
+			return nil
+		},
+		CloseTimeout: time.Millisecond * 100,
+	}
+
+	done, cancel := job.ExecuteWithCancel()
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, async.ErrCloseTimeout) {
+			t.Errorf("expected ErrCloseTimeout, got %v", err)
+		}
+	case <-time.After(time.Second * 2):
+		t.Error("expected Execute to return after CloseTimeout")
+	}
+}
+
+func TestJob_ExecuteRunReturns(t *testing.T) {
+	closed := false
+	job := async.Job{
+		Run: func() error {
+			return nil
+		},
+		Close: func() error {
+			closed = true
+			return nil
+		},
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- job.Execute()
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Error(err)
+		}
+	case <-time.After(time.Second * 2):
+		t.Fatal("expected Execute to return once Run returned")
+	}
+	if !closed {
+		t.Error("expected Close to be called")
+	}
+}
+
+func TestJob_ExecuteRunErrorsStillCloses(t *testing.T) {
+	closed := make(chan struct{})
+	job := async.Job{
+		Run: func() error {
+			return errors.New("some error")
+		},
+		Close: func() error {
+			close(closed)
+			return nil
+		},
+	}
+
+	// error expected here
+	err := job.Execute()
+	if err == nil {
+		t.Error(err)
+	}
+
+	select {
+	case <-closed:
+	case <-time.After(time.Second * 2):
+		t.Error("expected Close to be called after Run errored")
+	}
+}
+
+func TestJob_ExecuteRunPanics(t *testing.T) {
+	job := async.Job{
+		Run: func() error {
+			panic("boom")
+		},
+		Close: func() error {
+			return nil
+		},
+	}
+
+	// error expected here
+	err := job.Execute()
+	if err == nil || !strings.HasPrefix(err.Error(), "panic in Run: boom") {
+		t.Errorf("expected recovered panic from Run, got %v", err)
+	}
+}
+
+func TestJob_ExecuteSkipCloseOnPanic(t *testing.T) {
+	closed, finally := false, false
+	job := async.Job{
+		Run: func() error {
+			panic("boom")
+		},
+		Close: func() error {
+			closed = true
+			return nil
+		},
+		Finally: func() {
+			finally = true
+		},
+		SkipCloseOnPanic: true,
+	}
+
+	// error expected here
+	err := job.Execute()
+	if err == nil || !strings.HasPrefix(err.Error(), "panic in Run: boom") {
+		t.Errorf("expected recovered panic from Run, got %v", err)
+	}
+	if closed {
+		t.Error("expected Close to be skipped after a panic")
+	}
+	if !finally {
+		t.Error("expected Finally to run after a panic")
+	}
+}
+
+func TestJob_ExecuteFinally(t *testing.T) {
+	var events []string
+	job := async.Job{
+		Run: func() error {
+			return nil
+		},
+		Close: func() error {
+			events = append(events, "close")
+			return nil
+		},
+		Finally: func() {
+			events = append(events, "finally")
+		},
+	}
+
+	err := job.Execute()
+	if err != nil {
+		t.Error(err)
+	}
+	if !reflect.DeepEqual(events, []string{"close", "finally"}) {
+		t.Errorf("expected Finally after Close, got %v", events)
+	}
+}
+
+func TestJob_ExecuteClosePanics(t *testing.T) {
+	job := async.Job{
+		Run: func() error {
+			return nil
+		},
+		Close: func() error {
+			panic("boom")
+		},
+	}
+
+	// error expected here
+	err := job.Execute()
+	if err == nil || !strings.HasPrefix(err.Error(), "panic in Close: boom") {
+		t.Errorf("expected recovered panic from Close, got %v", err)
+	}
+}
+
+func TestJob_SignalToCloseNotStarted(t *testing.T) {
+	var job async.Job
+
+	err := job.SignalToClose()
+	if err != async.ErrNotStarted {
+		t.Errorf("expected ErrNotStarted, got %v", err)
+	}
+}
+
+func TestJob_SignalToCloseTwice(t *testing.T) {
+	stopped := make(chan struct{})
+	job := async.Job{
+		Run: func() error {
+			<-stopped
+			return nil
+		},
+		Close: func() error {
+			close(stopped)
+			return nil
+		},
+	}
+
+	sig, ack, _ := job.RunWithClose()
+	sig <- 1
+	for i := 0; i < 2; i++ {
+		if err := job.SignalToClose(); err != nil {
+			t.Error(err)
+		}
+	}
+	<-ack
+}
+
+func TestJob_ExecuteContext(t *testing.T) {
+	closed := false
+	stopped := make(chan struct{})
+	job := async.Job{
+		Run: func() error {
+			<-stopped
+			return nil
+		},
+		Close: func() error {
+			closed = true
+			close(stopped)
+			return nil
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+
+	err := job.ExecuteContext(ctx)
+	if err != nil {
+		t.Error(err)
+	}
+	if !closed {
+		t.Error("expected Close to be called")
+	}
+}
+
+func TestJob_ExecuteRunAndCloseWithErrors(t *testing.T) {
+	runErr := errors.New("run error")
+	closeErr := errors.New("close error")
+	job := async.Job{
+		Run: func() error {
+			return runErr
+		},
+		Close: func() error {
+			return closeErr
+		},
+	}
+
+	// both errors expected here
+	err := job.Execute()
+	if !errors.Is(err, runErr) || !errors.Is(err, closeErr) {
+		t.Errorf("expected both run and close errors, got %v", err)
+	}
+}
+
+func TestJob_OnStartOnStop(t *testing.T) {
+	var r asynctest.LifecycleRecorder
+	job := async.Job{
+		Run: func() error {
+			return nil
+		},
+		Close: func() error {
+			return errors.New("some error")
+		},
+	}
+	r.Attach(&job)
+
+	// error expected here
+	err := job.Execute()
+	if err == nil {
+		t.Error(err)
+	}
+	r.AssertSequence(t,
+		asynctest.Start,
+		asynctest.Exit,
+		asynctest.Closing,
+		asynctest.Close,
+		asynctest.Stop,
+		asynctest.Finally,
+		asynctest.Failed,
+	)
+}
+
+func TestJob_Logger(t *testing.T) {
+	var buf bytes.Buffer
+	job := async.Job{
+		Run: func() error {
+			return nil
+		},
+		Close: func() error {
+			return errors.New("some error")
+		},
+		Logger: log.New(&buf, "", 0),
+	}
+
+	// error expected here
+	err := job.Execute()
+	if err == nil {
+		t.Error(err)
+	}
+	want := "closing\nclose failed: some error\nclosed\n"
+	if buf.String() != want {
+		t.Errorf("expected log %q, got %q", want, buf.String())
+	}
+}
+
+func TestJob_ExecuteDefaultSignalsNotWritten(t *testing.T) {
+	job := async.Job{
+		Run: func() error {
+			return nil
+		},
+		Close: func() error {
+			return nil
+		},
+	}
+
+	err := job.Execute()
+	if err != nil {
+		t.Error(err)
+	}
+	if job.Signals != nil {
+		t.Errorf("expected Signals to be left unset, got %v", job.Signals)
+	}
+}
+
+func TestJob_Wait(t *testing.T) {
+	runErr := errors.New("run error")
+	closeErr := errors.New("close error")
+	job := async.Job{
+		Run: func() error {
+			return runErr
+		},
+		Close: func() error {
+			return closeErr
+		},
+	}
+
+	job.RunWithClose()
+
+	select {
+	case <-job.Done():
+	case <-time.After(time.Second * 2):
+		t.Fatal("expected Done to be closed")
+	}
+	err := job.Wait()
+	if !errors.Is(err, runErr) || !errors.Is(err, closeErr) {
+		t.Errorf("expected both run and close errors, got %v", err)
+	}
+}
+
+func TestJob_WaitNotStarted(t *testing.T) {
+	var job async.Job
+
+	if job.Done() != nil {
+		t.Error("expected nil Done channel")
+	}
+	if err := job.Wait(); err != async.ErrNotStarted {
+		t.Errorf("expected ErrNotStarted, got %v", err)
+	}
+}
+
+func TestJob_ExecuteForcedShutdown(t *testing.T) {
+	job := async.Job{
+		RunCtx: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		Close: func() error {
+			<-time.After(time.Second * 5)
+			return nil
+		},
+		Signals: []os.Signal{syscall.SIGUSR2},
+	}
+
+	go func() {
+		for i := 0; i < 2; i++ {
+			<-time.After(time.Millisecond * 100)
+			syscall.Kill(syscall.Getpid(), syscall.SIGUSR2)
+		}
+	}()
+
+	start := time.Now()
+	err := job.Execute()
+	if !errors.Is(err, async.ErrForcedShutdown) {
+		t.Errorf("expected ErrForcedShutdown, got %v", err)
+	}
+	if time.Since(start) > time.Second*2 {
+		t.Error("expected Execute to return without waiting for Close")
+	}
+}
+
+func TestJob_ExecuteAlreadyStarted(t *testing.T) {
+	job := async.Job{
+		RunCtx: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		Close: func() error {
+			return nil
+		},
+	}
+
+	_, ack, _ := job.RunWithClose()
+
+	// error expected here
+	err := job.Execute()
+	if err != async.ErrAlreadyStarted {
+		t.Errorf("expected ErrAlreadyStarted, got %v", err)
+	}
+	_, _, errs := job.RunWithClose()
+	if err := <-errs; err != async.ErrAlreadyStarted {
+		t.Errorf("expected ErrAlreadyStarted, got %v", err)
+	}
+
+	job.SignalToClose()
+	<-ack
+	<-job.Done()
+
+	// a finished job may be started again
+	closeSoon(&job)
+	if err := job.Execute(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestJob_CloseSignal(t *testing.T) {
+	var got []os.Signal
+	job := async.Job{
+		RunCtx: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		CloseSignal: func(s os.Signal) error {
+			got = append(got, s)
+			return nil
+		},
+		Signals: []os.Signal{syscall.SIGUSR1},
+	}
+
+	go func() {
+		<-time.After(time.Millisecond * 100)
+		syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	}()
+	if err := job.Execute(); err != nil {
+		t.Error(err)
+	}
+
+	// a close not triggered by a signal passes nil
+	closeSoon(&job)
+	if err := job.Execute(); err != nil {
+		t.Error(err)
+	}
+
+	want := []os.Signal{syscall.SIGUSR1, nil}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected CloseSignal with %v, got %v", want, got)
+	}
+}
+
+func TestJob_CloseWithReason(t *testing.T) {
+	errRun := errors.New("run failed")
+	var got []async.CloseReason
+	job := async.Job{
+		CloseWithReason: func(r async.CloseReason) error {
+			got = append(got, r)
+			return nil
+		},
+	}
+
+	job.RunCtx = func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	}
+	closeSoon(&job)
+	if err := job.Execute(); err != nil {
+		t.Error(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	if err := job.ExecuteContext(ctx); err != nil {
+		t.Error(err)
+	}
+
+	job.MaxRuntime = time.Millisecond * 50
+	// error expected here
+	if err := job.Execute(); !errors.Is(err, async.ErrDeadlineExceeded) {
+		t.Errorf("expected %v, got %v", async.ErrDeadlineExceeded, err)
+	}
+	job.MaxRuntime = 0
+
+	job.RunCtx = func(ctx context.Context) error {
+		return errRun
+	}
+	// error expected here
+	if err := job.Execute(); !errors.Is(err, errRun) {
+		t.Errorf("expected %v, got %v", errRun, err)
+	}
+
+	job.RunCtx = func(ctx context.Context) error {
+		return nil
+	}
+	if err := job.Execute(); err != nil {
+		t.Error(err)
+	}
+
+	want := []async.CloseReason{
+		async.CloseRequested,
+		async.CloseCanceled,
+		async.CloseDeadline,
+		async.CloseRunFailed,
+		async.CloseRunReturned,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected CloseWithReason with %v, got %v", want, got)
+	}
+}
+
+func TestJob_SignalHandlersDuringClose(t *testing.T) {
+	closing := make(chan struct{})
+	handled := make(chan struct{})
+	job := async.Job{
+		RunCtx: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		Close: func() error {
+			close(closing)
+			select {
+			case <-handled:
+			case <-time.After(time.Second * 2):
+				t.Error("expected SIGUSR1 to be handled while Close runs")
+			}
+			return nil
+		},
+		Signals: []os.Signal{syscall.SIGUSR2},
+		SignalHandlers: map[os.Signal]func(){
+			syscall.SIGUSR1: func() { close(handled) },
+		},
+	}
+
+	go func() {
+		<-time.After(time.Millisecond * 100)
+		job.SignalToClose()
+		<-closing
+		syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	}()
+
+	err := job.Execute()
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestJob_SignalHandlersNotValid(t *testing.T) {
+	job := async.Job{
+		Run: func() error {
+			return nil
+		},
+		Close: func() error {
+			return nil
+		},
+		SignalHandlers: map[os.Signal]func(){
+			syscall.SIGTERM: func() {},
+		},
+	}
+
+	// error expected here
+	err := job.Validate()
+	if err == nil {
+		t.Error(err)
+	}
+}
+
+func TestJob_ExecuteContextThenSignal(t *testing.T) {
+	job := async.Job{
+		RunCtx: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		Close: func() error {
+			<-time.After(time.Millisecond * 300)
+			return nil
+		},
+		Signals: []os.Signal{syscall.SIGUSR1},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	go func() {
+		<-time.After(time.Millisecond * 100)
+		syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	}()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- job.ExecuteContext(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Error(err)
+		}
+	case <-time.After(time.Second * 2):
+		t.Fatal("expected Execute to return once Close finished")
+	}
+}
+
+func TestJob_Drain(t *testing.T) {
+	var r asynctest.LifecycleRecorder
+	drainErr := errors.New("drain error")
+	job := async.Job{
+		Run: func() error {
+			return nil
+		},
+		Drain: func(ctx context.Context) error {
+			if _, ok := ctx.Deadline(); !ok {
+				t.Error("expected drain context to carry CloseTimeout")
+			}
+			return drainErr
+		},
+		Close: func() error {
+			return nil
+		},
+		CloseTimeout: time.Second,
+	}
+	r.Attach(&job)
+
+	// error expected here
+	err := job.Execute()
+	if !errors.Is(err, drainErr) {
+		t.Errorf("expected drain error, got %v", err)
+	}
+	r.AssertSequence(t,
+		asynctest.Start,
+		asynctest.Exit,
+		asynctest.Closing,
+		asynctest.Drain,
+		asynctest.Close,
+		asynctest.Stop,
+		asynctest.Finally,
+		asynctest.Failed,
+	)
+}
+
+// fakeConsumer drains its backlog one item at a time.
+type fakeConsumer struct {
+	mu      sync.Mutex
+	backlog int
+}
+
+func (c *fakeConsumer) Drain(ctx context.Context) error {
+	tick := time.NewTicker(time.Millisecond * 10)
+	defer tick.Stop()
+	for c.Backlog() > 0 {
+		select {
+		case <-tick.C:
+			c.mu.Lock()
+			c.backlog--
+			c.mu.Unlock()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (c *fakeConsumer) Backlog() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.backlog
+}
+
+func TestJob_DrainBacklog(t *testing.T) {
+	consumer := &fakeConsumer{backlog: 3}
+	job := async.Job{
+		Run: func() error {
+			return nil
+		},
+		Drain:   consumer.Drain,
+		Backlog: consumer.Backlog,
+		Close: func() error {
+			return nil
+		},
+		CloseTimeout: time.Second,
+	}
+
+	if err := job.Execute(); err != nil {
+		t.Error(err)
+	}
+	if n := consumer.Backlog(); n != 0 {
+		t.Errorf("expected the backlog to be drained, %d left", n)
+	}
+}
+
+func TestJob_DrainBacklogDeadline(t *testing.T) {
+	consumer := &fakeConsumer{backlog: 100}
+	job := async.Job{
+		Run: func() error {
+			return nil
+		},
+		Drain:   consumer.Drain,
+		Backlog: consumer.Backlog,
+		Close: func() error {
+			return nil
+		},
+		CloseTimeout: time.Millisecond * 200,
+	}
+
+	// error expected here
+	err := job.Execute()
+	var de *async.DrainError
+	if !errors.As(err, &de) || !errors.Is(err, async.ErrCloseTimeout) {
+		t.Fatalf("expected a DrainError with ErrCloseTimeout, got %v", err)
+	}
+	if de.Remaining <= 0 || de.Remaining >= 100 {
+		t.Errorf("expected part of the backlog to remain, got %d", de.Remaining)
+	}
+}
+
+func TestJob_ExecuteReload(t *testing.T) {
+	reloads := make(chan struct{}, 2)
+	job := async.Job{
+		RunCtx: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		Close: func() error {
+			return nil
+		},
+		ReloadSignals: []os.Signal{syscall.SIGHUP},
+		Reload: func() error {
+			reloads <- struct{}{}
+			return nil
+		},
+	}
+
+	go func() {
+		<-time.After(time.Millisecond * 100)
+		for i := 0; i < 2; i++ {
+			syscall.Kill(syscall.Getpid(), syscall.SIGHUP)
+			<-reloads
+		}
+		if s := job.State(); s != async.StateRunning {
+			t.Errorf("expected job to keep running after reloads, got %v", s)
+		}
+		job.SignalToClose()
+	}()
+
+	err := job.Execute()
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestJob_ExecuteReloadFatal(t *testing.T) {
+	closed := false
+	job := async.Job{
+		RunCtx: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		Close: func() error {
+			closed = true
+			return nil
+		},
+		ReloadSignals: []os.Signal{syscall.SIGHUP},
+		Reload: func() error {
+			return fmt.Errorf("%w: bad config", async.ErrReloadFatal)
+		},
+	}
+
+	go func() {
+		<-time.After(time.Millisecond * 100)
+		syscall.Kill(syscall.Getpid(), syscall.SIGHUP)
+	}()
+
+	// error expected here
+	err := job.Execute()
+	if !errors.Is(err, async.ErrReloadFatal) {
+		t.Errorf("expected fatal reload error, got %v", err)
+	}
+	if !closed {
+		t.Error("expected a fatal reload error to close the job")
+	}
+}
+
+func TestJob_Ready(t *testing.T) {
+	markReady := make(chan struct{})
+	stopped := make(chan struct{})
+	job := async.Job{
+		RunReady: func(ready func()) error {
+			<-markReady
+			ready()
+			<-stopped
+			return nil
+		},
+		Close: func() error {
+			close(stopped)
+			return nil
+		},
+	}
+
+	if job.Ready() != nil {
+		t.Error("expected no Ready channel before the job starts")
+	}
+	job.RunWithClose()
+
+	select {
+	case <-job.Ready():
+		t.Error("expected job not to be ready before ready is called")
+	case <-time.After(time.Millisecond * 100):
+	}
+
+	close(markReady)
+	select {
+	case <-job.Ready():
+	case <-time.After(time.Second * 2):
+		t.Error("expected job to be ready once ready is called")
+	}
+
+	job.SignalToClose()
+	if err := job.Wait(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestJob_ReadyOnLaunch(t *testing.T) {
+	job := async.Job{
+		RunCtx: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		Close: func() error {
+			return nil
+		},
+	}
+
+	job.RunWithClose()
+	select {
+	case <-job.Ready():
+	case <-time.After(time.Second * 2):
+		t.Error("expected job without RunReady to be ready once launched")
+	}
+
+	job.SignalToClose()
+	if err := job.Wait(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestJob_AddCloser(t *testing.T) {
+	var events []string
+	closerErr := errors.New("closer error")
+	job := async.Job{
+		Run: func() error {
+			return nil
+		},
+		Close: func() error {
+			events = append(events, "close")
+			return nil
+		},
+	}
+	job.AddCloser(func() error {
+		events = append(events, "db")
+		return nil
+	})
+	job.AddCloser(func() error {
+		events = append(events, "logs")
+		return closerErr
+	})
+	job.AddCloser(func() error {
+		events = append(events, "pidfile")
+		return nil
+	})
+
+	// error expected here
+	err := job.Execute()
+	if !errors.Is(err, closerErr) {
+		t.Errorf("expected closer error, got %v", err)
+	}
+	want := []string{"close", "pidfile", "logs", "db"}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("expected %v, got %v", want, events)
+	}
+}
+
+func TestJob_AddCloserWithoutClose(t *testing.T) {
+	closed := false
+	job := async.Job{
+		Run: func() error {
+			return nil
+		},
+	}
+	job.AddCloser(func() error {
+		closed = true
+		return nil
+	})
+
+	err := job.Execute()
+	if err != nil {
+		t.Error(err)
+	}
+	if !closed {
+		t.Error("expected the closer to run")
+	}
+}
+
+func TestJob_ExecuteMaxRuntime(t *testing.T) {
+	closed := false
+	job := async.Job{
+		RunCtx: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		Close: func() error {
+			closed = true
+			return nil
+		},
+		MaxRuntime:   time.Millisecond * 100,
+		CloseTimeout: time.Second,
+	}
+
+	// error expected here
+	err := job.Execute()
+	if !errors.Is(err, async.ErrDeadlineExceeded) {
+		t.Errorf("expected ErrDeadlineExceeded, got %v", err)
+	}
+	if !closed {
+		t.Error("expected Close to be called")
+	}
+}
+
+func TestJob_ExecuteMaxRuntimeNotReached(t *testing.T) {
+	job := async.Job{
+		Run: func() error {
+			return nil
+		},
+		Close: func() error {
+			return nil
+		},
+		MaxRuntime: time.Second,
+	}
+
+	err := job.Execute()
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestJob_ExecuteStartupTimeout(t *testing.T) {
+	closed := false
+	stop := make(chan struct{})
+	job := async.Job{
+		RunReady: func(ready func()) error {
+			<-stop
+			return nil
+		},
+		Close: func() error {
+			closed = true
+			close(stop)
+			return nil
+		},
+		StartupTimeout: time.Millisecond * 50,
+		MinUptime:      time.Hour,
+	}
+
+	// error expected here
+	err := job.Execute()
+	if !errors.Is(err, async.ErrStartupTimeout) {
+		t.Errorf("expected ErrStartupTimeout, got %v", err)
+	}
+	if !closed {
+		t.Error("expected Close to be called")
+	}
+}
+
+func TestJob_ExecuteStartupTimeoutReady(t *testing.T) {
+	stop := make(chan struct{})
+	job := async.Job{
+		RunReady: func(ready func()) error {
+			ready()
+			<-stop
+			return nil
+		},
+		Close: func() error {
+			close(stop)
+			return nil
+		},
+		StartupTimeout: time.Millisecond * 50,
+	}
+
+	closeSoon(&job)
+	if err := job.Execute(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestJob_ExecuteStartupTimeoutRestart(t *testing.T) {
+	var mu sync.Mutex
+	var stop chan struct{}
+	launches := make(chan int, 2)
+	n := 0
+	job := &async.Job{
+		RunReady: func(ready func()) error {
+			mu.Lock()
+			stop = make(chan struct{})
+			n++
+			launches <- n
+			if n == 1 {
+				// only the first launch becomes ready
+				ready()
+			}
+			s := stop
+			mu.Unlock()
+			<-s
+			return nil
+		},
+		Close: func() error {
+			mu.Lock()
+			defer mu.Unlock()
+			close(stop)
+			return nil
+		},
+		StartupTimeout: time.Millisecond * 50,
+	}
+
+	result := make(chan error, 1)
+	go func() { result <- job.Execute() }()
+	<-launches
+	<-job.Ready()
+	if err := job.Restart(); err != nil {
+		t.Error(err)
+	}
+	<-launches
+
+	select {
+	case err := <-result:
+		// error expected here
+		if !errors.Is(err, async.ErrStartupTimeout) {
+			t.Errorf("expected ErrStartupTimeout, got %v", err)
+		}
+	case <-time.After(time.Second * 2):
+		job.SignalToClose()
+		<-result
+		t.Error("expected the startup timeout to apply to the restarted run")
+	}
+}
+
+func TestJob_ExecuteErrorPhases(t *testing.T) {
+	runErr := errors.New("run error")
+	closeErr := errors.New("close error")
+	job := async.Job{
+		Run: func() error {
+			return runErr
+		},
+		Close: func() error {
+			return closeErr
+		},
+	}
+
+	// error expected here
+	err := job.Execute()
+	var re *async.RunError
+	if !errors.As(err, &re) || re.Err != runErr {
+		t.Errorf("expected a RunError wrapping the run error, got %v", err)
+	}
+	var ce *async.CloseError
+	if !errors.As(err, &ce) || ce.Err != closeErr {
+		t.Errorf("expected a CloseError wrapping the close error, got %v", err)
+	}
+}
@@ -0,0 +1,40 @@
+package asynctest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jharshman/async/asynctest"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Now()
+	clock := asynctest.NewFakeClock(start)
+	fired := clock.NewTimer(time.Minute)
+	stopped := clock.NewTimer(time.Minute)
+	later := clock.After(time.Hour)
+
+	if !stopped.Stop() {
+		t.Error("expected Stop to report the timer was pending")
+	}
+	clock.Advance(time.Minute)
+
+	select {
+	case now := <-fired.C():
+		if !now.Equal(start.Add(time.Minute)) {
+			t.Errorf("expected the timer to fire at %v, got %v", start.Add(time.Minute), now)
+		}
+	default:
+		t.Error("expected the timer to fire once due")
+	}
+	select {
+	case <-stopped.C():
+		t.Error("expected a stopped timer not to fire")
+	case <-later:
+		t.Error("expected a later timer not to fire yet")
+	default:
+	}
+	if got := clock.Now(); !got.Equal(start.Add(time.Minute)) {
+		t.Errorf("expected the clock to read %v, got %v", start.Add(time.Minute), got)
+	}
+}
@@ -0,0 +1,200 @@
+// Package asynctest provides helpers for testing code built on async.
+package asynctest
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/jharshman/async"
+)
+
+// Event is a step in a job's lifecycle recorded by a LifecycleRecorder.
+type Event string
+
+const (
+	// Start is recorded when Job.OnStart is called.
+	Start Event = "start"
+	// Signal is recorded when Job.OnSignal is called.
+	Signal Event = "signal"
+	// Closing is recorded when the job enters async.StateClosing.
+	Closing Event = "closing"
+	// Drain is recorded when Job.Drain is called.
+	Drain Event = "drain"
+	// Close is recorded when Job.Close, Job.CloseCtx, Job.CloseSignal or
+	// Job.CloseWithReason is called.
+	Close Event = "close"
+	// Exit is recorded each time the job's run function returns, which
+	// for a run that returns as soon as the close path has begun may be
+	// before or after Drain and Close. It always follows Start.
+	Exit Event = "exit"
+	// Stop is recorded when Job.OnStop is called.
+	Stop Event = "stop"
+	// Finally is recorded when Job.Finally is called.
+	Finally Event = "finally"
+	// Closed is recorded when the job enters async.StateClosed.
+	Closed Event = "closed"
+	// Failed is recorded when the job enters async.StateFailed.
+	Failed Event = "failed"
+)
+
+// LifecycleRecorder records the lifecycle events of the jobs attached
+// to it, in the order they happen.
+type LifecycleRecorder struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+// exit records Exit once Start has been recorded, as OnStart is called
+// concurrently with the run function. started is closed by OnStart.
+func (r *LifecycleRecorder) exit(started <-chan struct{}) {
+	<-started
+	r.record(Exit)
+}
+
+// Attach hooks r into j, wrapping any hooks and close functions already
+// set so that they still run. Attach must be called before j is started.
+func (r *LifecycleRecorder) Attach(j *async.Job) {
+	started := make(chan struct{})
+	var startOnce sync.Once
+	onStart := j.OnStart
+	j.OnStart = func() {
+		r.record(Start)
+		startOnce.Do(func() { close(started) })
+		if onStart != nil {
+			onStart()
+		}
+	}
+
+	onSignal := j.OnSignal
+	j.OnSignal = func(s os.Signal) {
+		r.record(Signal)
+		if onSignal != nil {
+			onSignal(s)
+		}
+	}
+
+	onStop := j.OnStop
+	j.OnStop = func() {
+		r.record(Stop)
+		if onStop != nil {
+			onStop()
+		}
+	}
+
+	finally := j.Finally
+	j.Finally = func() {
+		r.record(Finally)
+		if finally != nil {
+			finally()
+		}
+	}
+
+	onStateChange := j.OnStateChange
+	j.OnStateChange = func(s async.State) {
+		switch s {
+		case async.StateClosing:
+			r.record(Closing)
+		case async.StateClosed:
+			r.record(Closed)
+		case async.StateFailed:
+			r.record(Failed)
+		}
+		if onStateChange != nil {
+			onStateChange(s)
+		}
+	}
+
+	if drain := j.Drain; drain != nil {
+		j.Drain = func(ctx context.Context) error {
+			r.record(Drain)
+			return drain(ctx)
+		}
+	}
+
+	if closeCtx := j.CloseCtx; closeCtx != nil {
+		j.CloseCtx = func(ctx context.Context) error {
+			r.record(Close)
+			return closeCtx(ctx)
+		}
+	}
+	if closeSignal := j.CloseSignal; closeSignal != nil {
+		j.CloseSignal = func(s os.Signal) error {
+			r.record(Close)
+			return closeSignal(s)
+		}
+	}
+	if closeWithReason := j.CloseWithReason; closeWithReason != nil {
+		j.CloseWithReason = func(reason async.CloseReason) error {
+			r.record(Close)
+			return closeWithReason(reason)
+		}
+	}
+	if closeFn := j.Close; closeFn != nil {
+		j.Close = func() error {
+			r.record(Close)
+			return closeFn()
+		}
+	}
+
+	if run := j.Run; run != nil {
+		j.Run = func() error {
+			defer r.exit(started)
+			return run()
+		}
+	}
+	if runCtx := j.RunCtx; runCtx != nil {
+		j.RunCtx = func(ctx context.Context) error {
+			defer r.exit(started)
+			return runCtx(ctx)
+		}
+	}
+	if runWithCleanup := j.RunWithCleanup; runWithCleanup != nil {
+		j.RunWithCleanup = func(cleanup func(fn func() error)) error {
+			defer r.exit(started)
+			return runWithCleanup(cleanup)
+		}
+	}
+	if runWithStop := j.RunWithStop; runWithStop != nil {
+		j.RunWithStop = func(ctx context.Context, stop <-chan struct{}) error {
+			defer r.exit(started)
+			return runWithStop(ctx, stop)
+		}
+	}
+	if runReady := j.RunReady; runReady != nil {
+		j.RunReady = func(ready func()) error {
+			defer r.exit(started)
+			return runReady(ready)
+		}
+	}
+	if runControlled := j.RunControlled; runControlled != nil {
+		j.RunControlled = func(ctrl *async.Control) error {
+			defer r.exit(started)
+			return runControlled(ctrl)
+		}
+	}
+}
+
+func (r *LifecycleRecorder) record(e Event) {
+	r.mu.Lock()
+	r.events = append(r.events, e)
+	r.mu.Unlock()
+}
+
+// Events returns the events recorded so far.
+func (r *LifecycleRecorder) Events() []Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Event(nil), r.events...)
+}
+
+// AssertSequence fails t unless the events recorded so far are exactly
+// want, in order.
+func (r *LifecycleRecorder) AssertSequence(t testing.TB, want ...Event) {
+	t.Helper()
+	if got := r.Events(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected lifecycle %v, got %v", want, got)
+	}
+}
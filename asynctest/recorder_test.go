@@ -0,0 +1,85 @@
+package asynctest_test
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/jharshman/async"
+	"github.com/jharshman/async/asynctest"
+)
+
+func TestLifecycleRecorder_Attach(t *testing.T) {
+	var r asynctest.LifecycleRecorder
+	started := false
+	job := async.Job{
+		Run: func() error {
+			return nil
+		},
+		Close: func() error {
+			return nil
+		},
+		OnStart: func() {
+			started = true
+		},
+	}
+	r.Attach(&job)
+
+	err := job.Execute()
+	if err != nil {
+		t.Error(err)
+	}
+	if !started {
+		t.Error("expected the job's own OnStart to still be called")
+	}
+	r.AssertSequence(t,
+		asynctest.Start,
+		asynctest.Exit,
+		asynctest.Closing,
+		asynctest.Close,
+		asynctest.Stop,
+		asynctest.Finally,
+		asynctest.Closed,
+	)
+}
+
+func TestLifecycleRecorder_AttachSignal(t *testing.T) {
+	var r asynctest.LifecycleRecorder
+	var got os.Signal
+	stopped := make(chan struct{})
+	job := async.Job{
+		Run: func() error {
+			<-stopped
+			return nil
+		},
+		CloseSignal: func(s os.Signal) error {
+			got = s
+			close(stopped)
+			return nil
+		},
+		Signals: []os.Signal{syscall.SIGUSR1},
+	}
+	r.Attach(&job)
+
+	go func() {
+		<-time.After(time.Millisecond * 100)
+		syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	}()
+	if err := job.Execute(); err != nil {
+		t.Error(err)
+	}
+	if got != syscall.SIGUSR1 {
+		t.Errorf("expected CloseSignal to still be passed the signal, got %v", got)
+	}
+	r.AssertSequence(t,
+		asynctest.Start,
+		asynctest.Signal,
+		asynctest.Closing,
+		asynctest.Close,
+		asynctest.Exit,
+		asynctest.Stop,
+		asynctest.Finally,
+		asynctest.Closed,
+	)
+}
@@ -0,0 +1,147 @@
+package async
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ChainPolicy decides how a chain of jobs linked by Job.Next handles a
+// job that fails. It is read from the head of the chain.
+type ChainPolicy int
+
+const (
+	// FailFast stops the chain at the first job that fails and returns
+	// its error. The jobs after it are neither run nor closed.
+	FailFast ChainPolicy = iota
+	// ContinueOnError runs every job in the chain regardless of failures
+	// and returns all of their errors joined.
+	ContinueOnError
+	// StopButClose stops the chain at the first job that fails, like
+	// FailFast, but still closes each job after it that has been started
+	// outside the chain, as with RunWithClose, so that none is left
+	// running. Each is closed through its usual close path, closers and
+	// CloseTimeout included, and its errors are returned along with the
+	// rest. Jobs that were never started are left alone.
+	StopButClose
+)
+
+func (p ChainPolicy) String() string {
+	switch p {
+	case FailFast:
+		return "fail_fast"
+	case ContinueOnError:
+		return "continue_on_error"
+	case StopButClose:
+		return "stop_but_close"
+	}
+	return "unknown"
+}
+
+// executeChain executes j and then each job along Job.Next in turn,
+// handling failures according to j's ChainPolicy.
+// A signal or a receive on trigger closes the current job and stops
+// the chain. Jobs whose ShouldRun reports false are passed over, and
+// count as neither failed nor completed. When the chain stops because a
+// job failed, the jobs that completed before it are rolled back. opts
+// apply to j alone.
+func (j *Job) executeChain(trigger <-chan struct{}, opts runOptions) error {
+	if e := j.checkChain(); e != nil {
+		return e
+	}
+
+	var (
+		errs []error
+		done []chainJob
+	)
+	for i, job := 0, j; job != nil; i, job = i+1, job.Next {
+		if job.ShouldRun != nil && !job.ShouldRun() {
+			job.logger().Printf("skipped, ShouldRun returned false")
+			job.markExecuted()
+			opts = runOptions{}
+			continue
+		}
+		signaled, e := job.execute(trigger, opts)
+		opts = runOptions{}
+		if e != nil {
+			if j.Next == nil {
+				return e
+			}
+			errs = append(errs, fmt.Errorf("job %d in chain: %w", i, e))
+			switch j.ChainPolicy {
+			case FailFast:
+				return errors.Join(append(errs, rollBack(done)...)...)
+			case StopButClose:
+				errs = append(errs, closeRest(i+1, job.Next)...)
+				return errors.Join(append(errs, rollBack(done)...)...)
+			}
+		} else {
+			done = append(done, chainJob{Job: job, pos: i})
+		}
+		if signaled {
+			return errors.Join(errs...)
+		}
+
+		select {
+		case <-trigger:
+			return errors.Join(errs...)
+		default:
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// closeRest closes job and each job after it that is running, through
+// its close path, waiting for each to finish. It returns their errors
+// wrapped with their positions, counting from i.
+func closeRest(i int, job *Job) []error {
+	var errs []error
+	for ; job != nil; i, job = i+1, job.Next {
+		job.mu.Lock()
+		l := job.l
+		job.mu.Unlock()
+		if l == nil || l.finished() {
+			continue
+		}
+		job.SignalToClose()
+		if e := job.Wait(); e != nil {
+			errs = append(errs, fmt.Errorf("job %d in chain: %w", i, e))
+		}
+	}
+	return errs
+}
+
+// chainJob is a job in a Next chain, along with its position in it.
+type chainJob struct {
+	*Job
+	pos int
+}
+
+// rollBack calls Rollback on each of done, the jobs at the start of the
+// chain that completed before it failed, from the last to the first. It
+// returns their errors wrapped with their positions in the chain.
+func rollBack(done []chainJob) []error {
+	var errs []error
+	for i := len(done) - 1; i >= 0; i-- {
+		job := done[i]
+		if job.Rollback == nil {
+			continue
+		}
+		if e := recovered("Rollback", job.Rollback); e != nil {
+			errs = append(errs, fmt.Errorf("job %d in chain: rollback: %w", job.pos, e))
+		}
+	}
+	return errs
+}
+
+// checkChain returns an error if following Job.Next from j
+// revisits a job.
+func (j *Job) checkChain() error {
+	seen := map[*Job]bool{}
+	for i, job := 0, j; job != nil; i, job = i+1, job.Next {
+		if seen[job] {
+			return fmt.Errorf("cycle in Next chain at job %d", i)
+		}
+		seen[job] = true
+	}
+	return nil
+}
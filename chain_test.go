@@ -0,0 +1,385 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/jharshman/async"
+)
+
+// closeSoon closes job via SignalToClose after a short wait.
+func closeSoon(job *async.Job) {
+	go func() {
+		<-time.After(time.Millisecond * 100)
+		job.SignalToClose()
+	}()
+}
+
+func TestJob_ExecuteNext(t *testing.T) {
+	var ran []string
+	second := &async.Job{
+		Close: func() error {
+			ran = append(ran, "second")
+			return nil
+		},
+	}
+	second.Run = func() error {
+		closeSoon(second)
+		return nil
+	}
+	first := &async.Job{
+		Run: func() error {
+			return nil
+		},
+		Close: func() error {
+			ran = append(ran, "first")
+			return nil
+		},
+		Next: second,
+	}
+	closeSoon(first)
+
+	err := first.Execute()
+	if err != nil {
+		t.Error(err)
+	}
+	if len(ran) != 2 || ran[0] != "first" || ran[1] != "second" {
+		t.Errorf("expected jobs to run in chain order, got %v", ran)
+	}
+}
+
+func TestJob_ExecuteNextSignaled(t *testing.T) {
+	second := &async.Job{
+		Run: func() error {
+			t.Error("expected chain to stop after a signal")
+			return nil
+		},
+		Close: func() error {
+			return nil
+		},
+	}
+	first := &async.Job{
+		RunCtx: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		Close: func() error {
+			return nil
+		},
+		Signals: []os.Signal{syscall.SIGUSR1},
+		Next:    second,
+	}
+	go func() {
+		<-time.After(time.Millisecond * 100)
+		syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	}()
+
+	err := first.Execute()
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestJob_ExecuteNextWithErrors(t *testing.T) {
+	third := &async.Job{
+		Run: func() error {
+			t.Error("expected chain to stop before third job")
+			return nil
+		},
+		Close: func() error {
+			return nil
+		},
+	}
+	second := &async.Job{
+		Run: func() error {
+			return errors.New("some error")
+		},
+		Close: func() error {
+			return nil
+		},
+		Next: third,
+	}
+	first := &async.Job{
+		Run: func() error {
+			return nil
+		},
+		Close: func() error {
+			return nil
+		},
+		Next: second,
+	}
+	closeSoon(first)
+
+	// error expected here
+	err := first.Execute()
+	if err == nil {
+		t.Fatal(err)
+	}
+	if err.Error() != "job 1 in chain: some error" {
+		t.Errorf("expected error to identify job 1, got %v", err)
+	}
+}
+
+// policyChain returns a chain of three jobs whose middle job fails.
+// ran and closed record the jobs whose Run and Close were called.
+func policyChain(policy async.ChainPolicy, ran, closed *[]string) *async.Job {
+	job := func(name string, err error, next *async.Job) *async.Job {
+		return &async.Job{
+			Run: func() error {
+				*ran = append(*ran, name)
+				return err
+			},
+			Close: func() error {
+				*closed = append(*closed, name)
+				return nil
+			},
+			Next: next,
+		}
+	}
+	third := job("third", nil, nil)
+	second := job("second", errors.New("some error"), third)
+	first := job("first", nil, second)
+	first.ChainPolicy = policy
+	return first
+}
+
+func TestJob_ExecuteNextFailFast(t *testing.T) {
+	var ran, closed []string
+	first := policyChain(async.FailFast, &ran, &closed)
+
+	// error expected here
+	err := first.Execute()
+	if err == nil || err.Error() != "job 1 in chain: some error" {
+		t.Errorf("expected error from job 1, got %v", err)
+	}
+	if !reflect.DeepEqual(ran, []string{"first", "second"}) {
+		t.Errorf("expected chain to stop at job 1, ran %v", ran)
+	}
+	if !reflect.DeepEqual(closed, []string{"first", "second"}) {
+		t.Errorf("expected only started jobs to close, closed %v", closed)
+	}
+}
+
+func TestJob_ExecuteNextContinueOnError(t *testing.T) {
+	var ran, closed []string
+	first := policyChain(async.ContinueOnError, &ran, &closed)
+
+	// error expected here
+	err := first.Execute()
+	if err == nil || err.Error() != "job 1 in chain: some error" {
+		t.Errorf("expected error from job 1, got %v", err)
+	}
+	if !reflect.DeepEqual(ran, []string{"first", "second", "third"}) {
+		t.Errorf("expected every job to run, ran %v", ran)
+	}
+}
+
+func TestJob_ExecuteNextStopButClose(t *testing.T) {
+	var ran, closed []string
+	first := policyChain(async.StopButClose, &ran, &closed)
+
+	// error expected here
+	err := first.Execute()
+	if err == nil || err.Error() != "job 1 in chain: some error" {
+		t.Errorf("expected error from job 1, got %v", err)
+	}
+	if !reflect.DeepEqual(ran, []string{"first", "second"}) {
+		t.Errorf("expected chain to stop at job 1, ran %v", ran)
+	}
+	if !reflect.DeepEqual(closed, []string{"first", "second"}) {
+		t.Errorf("expected only started jobs to close, closed %v", closed)
+	}
+}
+
+func TestJob_ExecuteNextStopButCloseStarted(t *testing.T) {
+	errClose := errors.New("close failed")
+	var closerCalled, fourthClosed bool
+	stopped := make(chan struct{})
+	fourth := &async.Job{
+		Run: func() error {
+			return nil
+		},
+		Close: func() error {
+			fourthClosed = true
+			return nil
+		},
+	}
+	third := &async.Job{
+		Run: func() error {
+			<-stopped
+			return nil
+		},
+		Close: func() error {
+			close(stopped)
+			return errClose
+		},
+		CloseTimeout: time.Second,
+		Next:         fourth,
+	}
+	third.AddCloser(func() error {
+		closerCalled = true
+		return nil
+	})
+	second := &async.Job{
+		Run: func() error {
+			return errors.New("some error")
+		},
+		Close: func() error {
+			return nil
+		},
+		Next: third,
+	}
+	first := &async.Job{
+		Run: func() error {
+			return nil
+		},
+		Close: func() error {
+			return nil
+		},
+		ChainPolicy: async.StopButClose,
+		Next:        second,
+	}
+
+	// third has been started outside the chain
+	third.RunWithClose()
+
+	// error expected here
+	err := first.Execute()
+	if !errors.Is(err, errClose) {
+		t.Errorf("expected the close error of the started job, got %v", err)
+	}
+	if !closerCalled {
+		t.Error("expected the started job's closers to run")
+	}
+	if fourthClosed {
+		t.Error("expected a job that was never started not to be closed")
+	}
+	if s := third.State(); s != async.StateFailed {
+		t.Errorf("expected the started job to have closed, got %v", s)
+	}
+}
+
+func TestJob_ExecuteNextRollback(t *testing.T) {
+	errRun := errors.New("some error")
+	errRollback := errors.New("rollback failed")
+	var rolledBack []string
+	job := func(name string, runErr, rollbackErr error, next *async.Job) *async.Job {
+		return &async.Job{
+			Run: func() error {
+				return runErr
+			},
+			Close: func() error {
+				return nil
+			},
+			Rollback: func() error {
+				rolledBack = append(rolledBack, name)
+				return rollbackErr
+			},
+			Next: next,
+		}
+	}
+	fourth := job("fourth", nil, nil, nil)
+	third := job("third", errRun, nil, fourth)
+	second := job("second", nil, errRollback, third)
+	first := job("first", nil, nil, second)
+
+	// error expected here
+	err := first.Execute()
+	if !errors.Is(err, errRun) || !errors.Is(err, errRollback) {
+		t.Errorf("expected run and rollback errors joined, got %v", err)
+	}
+	if !reflect.DeepEqual(rolledBack, []string{"second", "first"}) {
+		t.Errorf("expected completed jobs to roll back in reverse, rolled back %v", rolledBack)
+	}
+}
+
+func TestJob_ExecuteNextRollbackSkipped(t *testing.T) {
+	errRollback := errors.New("rollback failed")
+	job := func(runErr, rollbackErr error, should bool, next *async.Job) *async.Job {
+		return &async.Job{
+			Run: func() error {
+				return runErr
+			},
+			Close: func() error {
+				return nil
+			},
+			Rollback: func() error {
+				return rollbackErr
+			},
+			ShouldRun: func() bool {
+				return should
+			},
+			Next: next,
+		}
+	}
+	fourth := job(errors.New("some error"), nil, true, nil)
+	third := job(nil, errRollback, true, fourth)
+	second := job(nil, nil, false, third)
+	first := job(nil, nil, true, second)
+
+	// error expected here
+	err := first.Execute()
+	if err == nil || !strings.Contains(err.Error(), "job 2 in chain: rollback: rollback failed") {
+		t.Errorf("expected the rollback error labelled with its position, got %v", err)
+	}
+}
+
+func TestJob_ExecuteNextShouldRun(t *testing.T) {
+	var ran []string
+	job := func(name string, should bool, next *async.Job) *async.Job {
+		return &async.Job{
+			Run: func() error {
+				ran = append(ran, name)
+				return nil
+			},
+			Close: func() error {
+				ran = append(ran, name+" closed")
+				return nil
+			},
+			ShouldRun: func() bool {
+				return should
+			},
+			Next: next,
+		}
+	}
+	third := job("third", true, nil)
+	second := job("second", false, third)
+	first := job("first", true, second)
+
+	if err := first.Execute(); err != nil {
+		t.Error(err)
+	}
+	want := []string{"first", "first closed", "third", "third closed"}
+	if !reflect.DeepEqual(ran, want) {
+		t.Errorf("expected %v, got %v", want, ran)
+	}
+}
+
+func TestJob_ExecuteNextCycle(t *testing.T) {
+	first := &async.Job{
+		Run: func() error {
+			return nil
+		},
+		Close: func() error {
+			return nil
+		},
+	}
+	second := &async.Job{
+		Run:   first.Run,
+		Close: first.Close,
+		Next:  first,
+	}
+	first.Next = second
+
+	// error expected here
+	err := first.Execute()
+	if err == nil {
+		t.Error(err)
+	}
+}
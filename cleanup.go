@@ -0,0 +1,35 @@
+package async
+
+import "sync"
+
+// cleanupStack holds cleanup functions registered by Job.RunWithCleanup.
+// It is safe for concurrent use.
+type cleanupStack struct {
+	mu  sync.Mutex
+	fns []func() error
+}
+
+// push registers fn to be called when the stack unwinds.
+func (c *cleanupStack) push(fn func() error) {
+	c.mu.Lock()
+	c.fns = append(c.fns, fn)
+	c.mu.Unlock()
+}
+
+// unwind calls the registered functions in LIFO order and empties the stack,
+// so each function runs at most once. All functions are called even if one
+// fails; the first error encountered is returned.
+func (c *cleanupStack) unwind() error {
+	c.mu.Lock()
+	fns := c.fns
+	c.fns = nil
+	c.mu.Unlock()
+
+	var err error
+	for i := len(fns) - 1; i >= 0; i-- {
+		if e := fns[i](); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
@@ -0,0 +1,102 @@
+package async
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Clock tells the time for the timeouts, delays and deadlines of a Job:
+// CloseTimeout, MaxRuntime, MinUptime, TelemetryFlushTimeout, StartupRetry
+// and Supervisor backoff. Tests can set a fake one with WithClock so
+// they need not wait in real time.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is a timer created by a Clock, like *time.Timer.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+// WithClock sets the Clock the job measures time with, which defaults to
+// the system clock.
+func WithClock(c Clock) Option {
+	return func(j *Job) {
+		j.clk = c
+	}
+}
+
+// realClock is the system clock.
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (realClock) NewTimer(d time.Duration) Timer         { return realTimer{time.NewTimer(d)} }
+
+type realTimer struct {
+	*time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+// clock returns the job's Clock, or the system clock if none is set.
+func (j *Job) clock() Clock {
+	if j.clk != nil {
+		return j.clk
+	}
+	return realClock{}
+}
+
+// since returns the time elapsed since t by the job's clock.
+func (j *Job) since(t time.Time) time.Duration {
+	return j.clock().Now().Sub(t)
+}
+
+// withTimeout is context.WithTimeout measured by the job's clock.
+func (j *Job) withTimeout(parent context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	c := j.clock()
+	if _, ok := c.(realClock); ok {
+		return context.WithTimeout(parent, d)
+	}
+
+	ctx, cancel := context.WithCancel(parent)
+	tc := &timeoutContext{Context: ctx, deadline: c.Now().Add(d)}
+	t := c.NewTimer(d)
+	go func() {
+		select {
+		case <-t.C():
+			tc.expired.Store(true)
+			cancel()
+		case <-ctx.Done():
+			t.Stop()
+		}
+	}()
+	return tc, cancel
+}
+
+// timeoutContext is a context cancelled by a Clock's timer, reporting
+// context.DeadlineExceeded once it has fired.
+type timeoutContext struct {
+	context.Context
+
+	deadline time.Time
+	expired  atomic.Bool
+}
+
+func (c *timeoutContext) Deadline() (time.Time, bool) {
+	return c.deadline, true
+}
+
+func (c *timeoutContext) Err() error {
+	e := c.Context.Err()
+	if e != nil && c.expired.Load() {
+		return context.DeadlineExceeded
+	}
+	return e
+}
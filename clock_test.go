@@ -0,0 +1,100 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jharshman/async"
+	"github.com/jharshman/async/asynctest"
+)
+
+func TestWithClockMaxRuntime(t *testing.T) {
+	clock := asynctest.NewFakeClock(time.Now())
+	job := &async.Job{
+		RunCtx: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		Close: func() error {
+			return nil
+		},
+		MaxRuntime: time.Hour,
+	}
+	async.WithClock(clock)(job)
+
+	go func() {
+		clock.BlockUntil(1)
+		clock.Advance(time.Hour)
+	}()
+
+	// error expected here
+	if err := job.Execute(); !errors.Is(err, async.ErrDeadlineExceeded) {
+		t.Errorf("expected %v, got %v", async.ErrDeadlineExceeded, err)
+	}
+}
+
+func TestWithClockCloseTimeout(t *testing.T) {
+	clock := asynctest.NewFakeClock(time.Now())
+	release := make(chan struct{})
+	defer close(release)
+	job := &async.Job{
+		Run: func() error {
+			<-release
+			return nil
+		},
+		Close: func() error {
+			<-release
+			return nil
+		},
+		CloseTimeout: time.Hour,
+	}
+	async.WithClock(clock)(job)
+
+	sig, _, err := job.RunWithClose()
+	sig <- 1
+	clock.BlockUntil(1)
+	clock.Advance(time.Hour)
+
+	// error expected here
+	if e := <-err; !errors.Is(e, async.ErrCloseTimeout) {
+		t.Errorf("expected %v, got %v", async.ErrCloseTimeout, e)
+	}
+}
+
+func TestWithClockSupervisorBackoff(t *testing.T) {
+	clock := asynctest.NewFakeClock(time.Now())
+	var runs int
+	s := async.Supervisor{
+		Job: &async.Job{
+			Run: func() error {
+				runs++
+				if runs == 1 {
+					return errors.New("some error")
+				}
+				return nil
+			},
+			Close: func() error {
+				return nil
+			},
+		},
+		MaxRestarts: 1,
+		Backoff: func(attempt int) time.Duration {
+			return time.Hour
+		},
+	}
+	async.WithClock(clock)(s.Job)
+
+	go func() {
+		clock.BlockUntil(1)
+		clock.Advance(time.Hour)
+	}()
+
+	if err := s.Execute(); err != nil {
+		t.Error(err)
+	}
+	if runs != 2 {
+		t.Errorf("expected Run to be restarted after the backoff, ran %d times", runs)
+	}
+}
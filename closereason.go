@@ -0,0 +1,57 @@
+package async
+
+// CloseReason identifies what triggered a job's close path. It is passed
+// to Job.CloseWithReason.
+type CloseReason int
+
+const (
+	// CloseRequested is the reason when the job was asked to close by
+	// SignalToClose, Stop, Restart or its group, or when nothing else
+	// was recorded.
+	CloseRequested CloseReason = iota
+	// CloseSignaled is the reason when Execute received one of the
+	// job's signals.
+	CloseSignaled
+	// CloseCanceled is the reason when the context passed to
+	// ExecuteContext, or the trigger of ExecuteWithCancel, was done.
+	CloseCanceled
+	// CloseRunFailed is the reason when Run returned an error.
+	CloseRunFailed
+	// CloseRunReturned is the reason when Run returned without error.
+	CloseRunReturned
+	// CloseDeadline is the reason when Job.MaxRuntime was exceeded.
+	CloseDeadline
+	// CloseLowDisk is the reason when free disk space dropped below
+	// Job.MinFreeDiskBytes.
+	CloseLowDisk
+	// CloseReloadFailed is the reason when Reload returned an error
+	// wrapping ErrReloadFatal.
+	CloseReloadFailed
+	// CloseStartupTimeout is the reason when the job did not become
+	// ready within Job.StartupTimeout.
+	CloseStartupTimeout
+)
+
+func (r CloseReason) String() string {
+	switch r {
+	case CloseRequested:
+		return "requested"
+	case CloseSignaled:
+		return "signaled"
+	case CloseCanceled:
+		return "canceled"
+	case CloseRunFailed:
+		return "run_failed"
+	case CloseRunReturned:
+		return "run_returned"
+	case CloseDeadline:
+		return "deadline"
+	case CloseLowDisk:
+		return "low_disk"
+	case CloseReloadFailed:
+		return "reload_failed"
+	case CloseStartupTimeout:
+		return "startup_timeout"
+	}
+	return "unknown"
+}
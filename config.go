@@ -0,0 +1,127 @@
+package async
+
+import (
+	"sort"
+	"time"
+)
+
+// Config is a serializable snapshot of a Job's configuration, suitable
+// for logging at startup or exposing on a debug endpoint.
+// Function fields are reported as whether they are set.
+type Config struct {
+	Name              string        `json:"name,omitempty"`
+	Signals           []string      `json:"signals,omitempty"`
+	SignalHandlers    []string      `json:"signal_handlers,omitempty"`
+	ReloadSignals     []string      `json:"reload_signals,omitempty"`
+	CloseTimeout      time.Duration `json:"close_timeout,omitempty"`
+	ErrBuffer         int           `json:"err_buffer,omitempty"`
+	AckBuffer         int           `json:"ack_buffer,omitempty"`
+	ErrHistory        int           `json:"err_history,omitempty"`
+	StrictClose       bool          `json:"strict_close,omitempty"`
+	SkipCloseOnPanic  bool          `json:"skip_close_on_panic,omitempty"`
+	MinUptime         time.Duration `json:"min_uptime,omitempty"`
+	MaxRuntime        time.Duration `json:"max_runtime,omitempty"`
+	StartupTimeout    time.Duration `json:"startup_timeout,omitempty"`
+	MinFreeDiskBytes  uint64        `json:"min_free_disk_bytes,omitempty"`
+	WatchPath         string        `json:"watch_path,omitempty"`
+	DiskCheckInterval time.Duration `json:"disk_check_interval,omitempty"`
+
+	TelemetryFlushTimeout time.Duration `json:"telemetry_flush_timeout,omitempty"`
+
+	StartupRetryAttempts int           `json:"startup_retry_attempts,omitempty"`
+	StartupRetryGrace    time.Duration `json:"startup_retry_grace,omitempty"`
+
+	// DependsOn is the number of jobs this job depends on.
+	DependsOn int `json:"depends_on,omitempty"`
+
+	ChainPolicy string `json:"chain_policy"`
+
+	Run             bool `json:"run"`
+	Close           bool `json:"close"`
+	RunCtx          bool `json:"run_ctx"`
+	CloseCtx        bool `json:"close_ctx"`
+	CloseSignal     bool `json:"close_signal"`
+	CloseWithReason bool `json:"close_with_reason"`
+	RunWithCleanup  bool `json:"run_with_cleanup"`
+	RunWithStop     bool `json:"run_with_stop"`
+	RunReady        bool `json:"run_ready"`
+	RunControlled   bool `json:"run_controlled"`
+	Drain           bool `json:"drain"`
+	Backlog         bool `json:"backlog"`
+	Reload          bool `json:"reload"`
+	OnStart         bool `json:"on_start"`
+	OnStop          bool `json:"on_stop"`
+	OnClosingSoon   bool `json:"on_closing_soon"`
+	OnSignal        bool `json:"on_signal"`
+	OnStateChange   bool `json:"on_state_change"`
+	Finally         bool `json:"finally"`
+	Rollback        bool `json:"rollback"`
+	ShouldRun       bool `json:"should_run"`
+	TelemetryFlush  bool `json:"telemetry_flush"`
+	Logger          bool `json:"logger"`
+	Metrics         bool `json:"metrics"`
+}
+
+// ConfigSnapshot returns the job's current configuration.
+func (j *Job) ConfigSnapshot() Config {
+	c := Config{
+		Name:              j.Name,
+		CloseTimeout:      j.CloseTimeout,
+		ErrBuffer:         j.ErrBuffer,
+		AckBuffer:         j.AckBuffer,
+		ErrHistory:        j.ErrHistory,
+		StrictClose:       j.StrictClose,
+		SkipCloseOnPanic:  j.SkipCloseOnPanic,
+		MinUptime:         j.MinUptime,
+		MaxRuntime:        j.MaxRuntime,
+		StartupTimeout:    j.StartupTimeout,
+		MinFreeDiskBytes:  j.MinFreeDiskBytes,
+		WatchPath:         j.WatchPath,
+		DiskCheckInterval: j.DiskCheckInterval,
+
+		TelemetryFlushTimeout: j.TelemetryFlushTimeout,
+
+		StartupRetryAttempts: j.StartupRetry.Attempts,
+		StartupRetryGrace:    j.StartupRetry.Grace,
+
+		DependsOn: len(j.DependsOn),
+
+		ChainPolicy: j.ChainPolicy.String(),
+
+		Run:             j.Run != nil,
+		Close:           j.Close != nil,
+		RunCtx:          j.RunCtx != nil,
+		CloseCtx:        j.CloseCtx != nil,
+		CloseSignal:     j.CloseSignal != nil,
+		CloseWithReason: j.CloseWithReason != nil,
+		RunWithCleanup:  j.RunWithCleanup != nil,
+		RunWithStop:     j.RunWithStop != nil,
+		RunReady:        j.RunReady != nil,
+		RunControlled:   j.RunControlled != nil,
+		Drain:           j.Drain != nil,
+		Backlog:         j.Backlog != nil,
+		Reload:          j.Reload != nil,
+		OnStart:         j.OnStart != nil,
+		OnStop:          j.OnStop != nil,
+		OnClosingSoon:   j.OnClosingSoon != nil,
+		OnSignal:        j.OnSignal != nil,
+		OnStateChange:   j.OnStateChange != nil,
+		Finally:         j.Finally != nil,
+		Rollback:        j.Rollback != nil,
+		ShouldRun:       j.ShouldRun != nil,
+		TelemetryFlush:  j.TelemetryFlush != nil,
+		Logger:          j.Logger != nil,
+		Metrics:         j.Metrics != nil,
+	}
+	for _, s := range j.Signals {
+		c.Signals = append(c.Signals, s.String())
+	}
+	for s := range j.SignalHandlers {
+		c.SignalHandlers = append(c.SignalHandlers, s.String())
+	}
+	sort.Strings(c.SignalHandlers)
+	for _, s := range j.ReloadSignals {
+		c.ReloadSignals = append(c.ReloadSignals, s.String())
+	}
+	return c
+}
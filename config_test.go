@@ -0,0 +1,37 @@
+package async_test
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/jharshman/async"
+)
+
+func TestJob_ConfigSnapshot(t *testing.T) {
+	job := async.Job{
+		Run: func() error {
+			return nil
+		},
+		Signals:   []os.Signal{syscall.SIGHUP},
+		MinUptime: time.Second,
+	}
+
+	got := job.ConfigSnapshot()
+	want := async.Config{
+		Signals:     []string{syscall.SIGHUP.String()},
+		MinUptime:   time.Second,
+		ChainPolicy: "fail_fast",
+		Run:         true,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+
+	if _, err := json.Marshal(got); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,65 @@
+package async
+
+import "context"
+
+// Control is handed to Job.RunControlled so that a polling or queue
+// processing loop can honour Pause and Resume.
+type Control struct {
+	job *Job
+	ctx context.Context
+}
+
+// Context returns the run context, which is cancelled when the close
+// path begins, as for Job.RunCtx.
+func (c *Control) Context() context.Context {
+	return c.ctx
+}
+
+// Wait blocks while the job is paused. It returns nil once the job is
+// not paused, or the run context's error once the job has begun closing,
+// so a loop calling Wait between iterations stops on either.
+func (c *Control) Wait() error {
+	if e := c.ctx.Err(); e != nil {
+		return e
+	}
+	c.job.mu.Lock()
+	resumed := c.job.resumed
+	c.job.mu.Unlock()
+	if resumed == nil {
+		return nil
+	}
+	select {
+	case <-resumed:
+		return nil
+	case <-c.ctx.Done():
+		return c.ctx.Err()
+	}
+}
+
+// Pause asks the job to stop processing at its next call to
+// Control.Wait, without closing it. It has no effect unless the job uses
+// RunControlled, and lasts until Resume is called, across runs.
+func (j *Job) Pause() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.resumed == nil {
+		j.resumed = make(chan struct{})
+	}
+}
+
+// Resume lets a paused job carry on, releasing any Control.Wait.
+func (j *Job) Resume() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.resumed != nil {
+		close(j.resumed)
+		j.resumed = nil
+	}
+}
+
+// Paused reports whether the job has been paused and not resumed.
+func (j *Job) Paused() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.resumed != nil
+}
@@ -0,0 +1,56 @@
+package async_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jharshman/async"
+)
+
+func TestJob_PauseResume(t *testing.T) {
+	var iterations atomic.Int32
+	job := async.Job{
+		RunControlled: func(ctrl *async.Control) error {
+			for ctrl.Wait() == nil {
+				iterations.Add(1)
+				<-time.After(time.Millisecond)
+			}
+			return nil
+		},
+		Close: func() error {
+			return nil
+		},
+	}
+	_, ack, _ := job.RunWithClose()
+
+	<-time.After(time.Millisecond * 20)
+	job.Pause()
+	if !job.Paused() {
+		t.Error("expected the job to be paused")
+	}
+	<-time.After(time.Millisecond * 10)
+	paused := iterations.Load()
+	<-time.After(time.Millisecond * 30)
+	if n := iterations.Load(); n != paused {
+		t.Errorf("expected no iterations while paused, got %d more", n-paused)
+	}
+
+	job.Resume()
+	if job.Paused() {
+		t.Error("expected the job to be resumed")
+	}
+	<-time.After(time.Millisecond * 30)
+	if n := iterations.Load(); n == paused {
+		t.Error("expected iterations to carry on once resumed")
+	}
+
+	// a paused job still closes
+	job.Pause()
+	job.SignalToClose()
+	select {
+	case <-ack:
+	case <-time.After(time.Second * 2):
+		t.Error("expected the paused job to close")
+	}
+}
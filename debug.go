@@ -0,0 +1,105 @@
+package async
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// Debug is the report rendered by Job.DebugHandler.
+type Debug struct {
+	Status
+
+	// Uptime is how long the latest run has been running, in seconds,
+	// and is zero once it has finished.
+	Uptime float64 `json:"uptime_seconds"`
+
+	// Signals are the signals Execute notifies on.
+	Signals []string `json:"signals"`
+
+	// PendingTriggers lists the triggers that have been received but
+	// not yet acted on: "close" for a close request the close path has
+	// not taken, "deferred_close" for a close held back by MinUptime,
+	// and "restart" for a Restart in progress.
+	PendingTriggers []string `json:"pending_triggers"`
+
+	// DeferredUntil is when a deferred close takes effect, if one is
+	// pending.
+	DeferredUntil *time.Time `json:"deferred_until,omitempty"`
+
+	// Events are the latest events, oldest first.
+	Events []DebugEvent `json:"events"`
+}
+
+// DebugEvent is an Event as reported by Job.DebugHandler.
+type DebugEvent struct {
+	Kind      string    `json:"kind"`
+	Timestamp time.Time `json:"timestamp"`
+	Err       string    `json:"err,omitempty"`
+	Signal    string    `json:"signal,omitempty"`
+}
+
+// Debug returns a snapshot of the job's internals, for Job.DebugHandler.
+// It is safe to call concurrently while the job runs.
+func (j *Job) Debug() Debug {
+	d := Debug{
+		Status:          j.Status(),
+		PendingTriggers: []string{},
+	}
+	for _, s := range j.signals() {
+		d.Signals = append(d.Signals, s.String())
+	}
+
+	j.mu.Lock()
+	l := j.l
+	if j.sig != nil && len(*j.sig) > 0 {
+		d.PendingTriggers = append(d.PendingTriggers, "close")
+	}
+	recent := append([]Event(nil), j.recent...)
+	j.mu.Unlock()
+
+	d.Events = make([]DebugEvent, len(recent))
+	for i, ev := range recent {
+		d.Events[i] = DebugEvent{
+			Kind:      ev.Kind.String(),
+			Timestamp: ev.Timestamp,
+		}
+		if ev.Err != nil {
+			d.Events[i].Err = ev.Err.Error()
+		}
+		if ev.Signal != nil {
+			d.Events[i].Signal = ev.Signal.String()
+		}
+	}
+
+	if l == nil {
+		return d
+	}
+	if !l.finished() {
+		d.Uptime = j.since(l.started).Seconds()
+	}
+	l.mu.Lock()
+	if !l.deferredUntil.IsZero() {
+		d.PendingTriggers = append(d.PendingTriggers, "deferred_close")
+		t := l.deferredUntil
+		d.DeferredUntil = &t
+	}
+	if l.restart != nil {
+		d.PendingTriggers = append(d.PendingTriggers, "restart")
+	}
+	l.mu.Unlock()
+	return d
+}
+
+// DebugHandler returns a handler that renders the job's Debug report as
+// JSON. The report includes error messages and other internals, so the
+// handler should only be mounted on a port that is not publicly
+// reachable.
+func (j *Job) DebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if e := json.NewEncoder(w).Encode(j.Debug()); e != nil {
+			http.Error(w, e.Error(), http.StatusInternalServerError)
+		}
+	})
+}
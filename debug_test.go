@@ -0,0 +1,104 @@
+package async_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jharshman/async"
+	"github.com/jharshman/async/asynctest"
+)
+
+func TestJob_DebugHandler(t *testing.T) {
+	clock := asynctest.NewFakeClock(time.Now())
+	stopped := make(chan struct{})
+	job := &async.Job{
+		Name: "worker",
+		Run: func() error {
+			<-stopped
+			return nil
+		},
+		Close: func() error {
+			close(stopped)
+			return nil
+		},
+	}
+	async.WithClock(clock)(job)
+
+	sig, ack, _ := job.RunWithClose()
+	clock.Advance(time.Minute)
+
+	srv := httptest.NewServer(job.DebugHandler())
+	defer srv.Close()
+	resp, err := srv.Client().Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected JSON, got %q", ct)
+	}
+	var got struct {
+		Name    string   `json:"name"`
+		State   string   `json:"state"`
+		Uptime  float64  `json:"uptime_seconds"`
+		Signals []string `json:"signals"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "worker" || got.State != "running" {
+		t.Errorf("expected a running worker, got %+v", got)
+	}
+	if got.Uptime != 60 {
+		t.Errorf("expected an uptime of 60 seconds, got %v", got.Uptime)
+	}
+	if len(got.Signals) == 0 {
+		t.Error("expected the effective signals")
+	}
+
+	sig <- 1
+	<-ack
+}
+
+func TestJob_DebugPendingTriggers(t *testing.T) {
+	started := make(chan struct{})
+	deferred := make(chan struct{})
+	stopped := make(chan struct{})
+	job := &async.Job{
+		Run: func() error {
+			<-stopped
+			return nil
+		},
+		Close: func() error {
+			return nil
+		},
+		MinUptime: time.Hour,
+		OnStart: func() {
+			close(started)
+		},
+		OnClosingSoon: func(time.Duration) {
+			close(deferred)
+		},
+	}
+
+	done, cancel := job.ExecuteWithCancel()
+	defer cancel()
+	<-started
+	cancel()
+	<-deferred
+
+	d := job.Debug()
+	if len(d.PendingTriggers) != 1 || d.PendingTriggers[0] != "deferred_close" || d.DeferredUntil == nil {
+		t.Errorf("expected a deferred close, got %v", d.PendingTriggers)
+	}
+	if len(d.Events) == 0 || d.Events[0].Kind != "started" {
+		t.Errorf("expected the started event first, got %+v", d.Events)
+	}
+
+	close(stopped)
+	if err := <-done; err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,33 @@
+package async
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrLowDisk is returned by Execute when the job was closed because free
+// space on Job.WatchPath fell below Job.MinFreeDiskBytes.
+var ErrLowDisk = errors.New("free disk space below threshold")
+
+// defaultDiskCheckInterval is used when Job.DiskCheckInterval is zero.
+const defaultDiskCheckInterval = 10 * time.Second
+
+// freeDiskSpace reports the bytes available to the calling user on the
+// filesystem containing path. It is a variable so tests can stub it.
+var freeDiskSpace = freeDiskBytes
+
+// diskCheckInterval returns the effective interval between free space checks.
+func (j *Job) diskCheckInterval() time.Duration {
+	if j.DiskCheckInterval > 0 {
+		return j.DiskCheckInterval
+	}
+	return defaultDiskCheckInterval
+}
+
+// lowDisk reports whether free space on Job.WatchPath is below
+// Job.MinFreeDiskBytes. A failure to sample free space is not treated
+// as low disk.
+func (j *Job) lowDisk() bool {
+	free, err := freeDiskSpace(j.WatchPath)
+	return err == nil && free < j.MinFreeDiskBytes
+}
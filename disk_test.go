@@ -0,0 +1,52 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestJob_ExecuteLowDisk(t *testing.T) {
+	defer func(f func(string) (uint64, error)) { freeDiskSpace = f }(freeDiskSpace)
+
+	free := uint64(3 << 20)
+	freeDiskSpace = func(path string) (uint64, error) {
+		free -= 1 << 20
+		return free, nil
+	}
+
+	closed := false
+	job := Job{
+		RunCtx: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		Close: func() error {
+			closed = true
+			return nil
+		},
+		MinFreeDiskBytes:  1 << 20,
+		WatchPath:         "/",
+		DiskCheckInterval: time.Millisecond * 10,
+	}
+
+	// error expected here
+	err := job.Execute()
+	if !errors.Is(err, ErrLowDisk) {
+		t.Errorf("expected ErrLowDisk, got %v", err)
+	}
+	if !closed {
+		t.Error("expected Close to be called")
+	}
+}
+
+func Test_freeDiskBytes(t *testing.T) {
+	free, err := freeDiskBytes(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if free == 0 {
+		t.Error("expected non-zero free space")
+	}
+}
@@ -0,0 +1,76 @@
+package async
+
+import (
+	"fmt"
+	"time"
+)
+
+// RunError wraps an error reported by the run side of a job: Job.Run or
+// one of its variants, including a recovered panic. Use errors.As to
+// tell it apart from a CloseError.
+type RunError struct {
+	Err error
+}
+
+func (e *RunError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RunError) Unwrap() error {
+	return e.Err
+}
+
+// CloseError wraps an error reported by the close side of a job:
+// Job.Drain, Job.Close, closers and cleanups, or ErrCloseTimeout.
+type CloseError struct {
+	Err error
+}
+
+func (e *CloseError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *CloseError) Unwrap() error {
+	return e.Err
+}
+
+// DrainError is reported alongside ErrCloseTimeout when the deadline
+// passes with items Job.Drain has yet to process, as counted by
+// Job.Backlog.
+type DrainError struct {
+	Remaining int
+}
+
+func (e *DrainError) Error() string {
+	return fmt.Sprintf("drain deadline passed with %d items unprocessed", e.Remaining)
+}
+
+// TimestampedError is an error a job reported, along with when, as
+// returned by Job.RecentErrors.
+type TimestampedError struct {
+	Err  error
+	Time time.Time
+}
+
+// remember adds e to the job's error history, keeping the latest
+// Job.ErrHistory errors.
+func (j *Job) remember(e error) {
+	if j.ErrHistory <= 0 {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.history = append(j.history, TimestampedError{Err: e, Time: j.clock().Now()})
+	if len(j.history) > j.ErrHistory {
+		j.history = j.history[len(j.history)-j.ErrHistory:]
+	}
+}
+
+// RecentErrors returns the latest errors the job reported, or restarted
+// after, oldest first. At most Job.ErrHistory errors are kept, across
+// runs; none are kept if it is zero.
+func (j *Job) RecentErrors() []TimestampedError {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return append([]TimestampedError(nil), j.history...)
+}
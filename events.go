@@ -0,0 +1,95 @@
+package async
+
+import (
+	"os"
+	"time"
+)
+
+// EventKind identifies what happened in an Event.
+type EventKind int
+
+const (
+	// EventStarted is published once Run has been launched.
+	EventStarted EventKind = iota
+	// EventSignalReceived is published when a signal that closes the
+	// job arrives.
+	EventSignalReceived
+	// EventClosing is published when the close path begins.
+	EventClosing
+	// EventClosed is published once the close path has finished,
+	// successfully or not.
+	EventClosed
+	// EventErrored is published for each error Run or Close reports.
+	EventErrored
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventStarted:
+		return "started"
+	case EventSignalReceived:
+		return "signal_received"
+	case EventClosing:
+		return "closing"
+	case EventClosed:
+		return "closed"
+	case EventErrored:
+		return "errored"
+	}
+	return "unknown"
+}
+
+// Event describes a transition in a Job's lifecycle.
+type Event struct {
+	Kind      EventKind
+	Timestamp time.Time
+
+	// Err is the reported error, for EventErrored.
+	Err error
+
+	// Signal is the signal received, for EventSignalReceived.
+	Signal os.Signal
+}
+
+// eventsBuffer is the capacity of the channel returned by Events.
+const eventsBuffer = 32
+
+// Events returns a channel on which the job's lifecycle events are sent,
+// in order, starting from the next one. Every call returns the same
+// channel, which is never closed.
+//
+// As with StateChanges, the channel is buffered and sends never block
+// the job: once the buffer is full, further events are dropped until the
+// consumer catches up.
+func (j *Job) Events() <-chan Event {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.events == nil {
+		j.events = make(chan Event, eventsBuffer)
+	}
+	return j.events
+}
+
+// recentEvents is how many of the latest events DebugHandler reports.
+const recentEvents = 16
+
+// publish sends ev on the Events channel, if there is one, stamping it
+// with the current time, and keeps it for DebugHandler. It must not be
+// called with j.mu held.
+func (j *Job) publish(ev Event) {
+	ev.Timestamp = j.clock().Now()
+	j.mu.Lock()
+	j.recent = append(j.recent, ev)
+	if len(j.recent) > recentEvents {
+		j.recent = j.recent[len(j.recent)-recentEvents:]
+	}
+	events := j.events
+	j.mu.Unlock()
+	if events == nil {
+		return
+	}
+	select {
+	case events <- ev:
+	default:
+	}
+}
@@ -0,0 +1,81 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/jharshman/async"
+)
+
+// received returns the kinds of the events already sent on events,
+// failing t for any event without a timestamp.
+func received(t *testing.T, events <-chan async.Event) []async.EventKind {
+	var got []async.EventKind
+	for {
+		select {
+		case ev := <-events:
+			if ev.Timestamp.IsZero() {
+				t.Errorf("expected %v event to have a timestamp", ev.Kind)
+			}
+			got = append(got, ev.Kind)
+		default:
+			return got
+		}
+	}
+}
+
+func TestJob_Events(t *testing.T) {
+	errRun := errors.New("run failed")
+	job := async.Job{
+		Run: func() error {
+			return errRun
+		},
+		Close: func() error {
+			return nil
+		},
+	}
+	events := job.Events()
+
+	// error expected here
+	if err := job.Execute(); !errors.Is(err, errRun) {
+		t.Errorf("expected %v, got %v", errRun, err)
+	}
+
+	want := []async.EventKind{async.EventStarted, async.EventErrored, async.EventClosing, async.EventClosed}
+	if got := received(t, events); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestJob_EventsSignalReceived(t *testing.T) {
+	job := async.Job{
+		RunCtx: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		Close: func() error {
+			return nil
+		},
+		Signals: []os.Signal{syscall.SIGUSR1},
+	}
+	events := job.Events()
+
+	go func() {
+		<-time.After(time.Millisecond * 100)
+		syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	}()
+
+	if err := job.Execute(); err != nil {
+		t.Error(err)
+	}
+
+	want := []async.EventKind{async.EventStarted, async.EventSignalReceived, async.EventClosing, async.EventClosed}
+	if got := received(t, events); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,100 @@
+package async
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Main is the main() boilerplate for a simple daemon. It calls Execute,
+// writes any error to stderr, and exits the process with a status
+// computed from the error: 0 on success, 1 otherwise.
+//
+// Main calls os.Exit, so deferred functions in the caller will not run.
+func (j *Job) Main() {
+	osExit(j.main(os.Stderr))
+}
+
+// MustExecute calls Execute and, if it fails, writes the error to stderr
+// and exits the process with status 1. Unlike Main, it returns when the
+// job succeeds, so main can carry on after it.
+//
+// On failure MustExecute calls os.Exit, so deferred functions in the
+// caller will not run.
+func (j *Job) MustExecute() {
+	if code := j.main(os.Stderr); code != 0 {
+		osExit(code)
+	}
+}
+
+// osExit exits the process. Tests replace it to observe the status.
+var osExit = os.Exit
+
+// RunMain runs appMain and then calls Job.Close and Job.Finally, both
+// when appMain returns normally and when it returns an error, so the
+// job's cleanup runs on every return path out of main. Any error is written to stderr and
+// RunMain returns the exit status for it, to be passed to os.Exit.
+//
+// If appMain also executes the job, neither is called a second time.
+func (j *Job) RunMain(appMain func() error) int {
+	return j.runMain(appMain, os.Stderr)
+}
+
+// runMain implements RunMain, writing errors to w.
+func (j *Job) runMain(appMain func() error, w io.Writer) int {
+	err := func() (err error) {
+		defer func() {
+			if e := j.closeOnce(); e != nil && err == nil {
+				err = e
+			}
+		}()
+		return appMain()
+	}()
+	if err != nil {
+		fmt.Fprintln(w, err)
+	}
+	return exitCode(err)
+}
+
+// closeOnce calls the job's close function, and then Job.Finally,
+// unless the latest lifecycle started by RunWithClose has already called
+// it; that lifecycle calls Finally itself.
+func (j *Job) closeOnce() error {
+	j.mu.Lock()
+	l := j.l
+	j.mu.Unlock()
+
+	closed := false
+	closeFn := func() error {
+		closed = true
+		return j.closeAll(context.Background(), nil, CloseRequested)
+	}
+	var e error
+	if l == nil {
+		e = closeFn()
+	} else {
+		e = l.closeOnce(j.StrictClose, closeFn)
+	}
+	if closed {
+		j.finally()
+	}
+	return e
+}
+
+// main implements Main without exiting, returning the exit status.
+func (j *Job) main(w io.Writer) int {
+	err := j.Execute()
+	if err != nil {
+		fmt.Fprintln(w, err)
+	}
+	return exitCode(err)
+}
+
+// exitCode maps the result of Execute to a process exit status.
+func exitCode(err error) int {
+	if err != nil {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,295 @@
+package async
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestJob_main(t *testing.T) {
+	var stderr bytes.Buffer
+	job := Job{
+		Run: func() error {
+			return errors.New("some error")
+		},
+		Close: func() error {
+			return nil
+		},
+	}
+
+	if code := job.main(&stderr); code != 1 {
+		t.Errorf("expected exit status 1, got %d", code)
+	}
+	if stderr.String() != "some error\n" {
+		t.Errorf("expected error on stderr, got %q", stderr.String())
+	}
+}
+
+func TestJob_mainNotValid(t *testing.T) {
+	var stderr bytes.Buffer
+	job := Job{}
+
+	if code := job.main(&stderr); code != 1 {
+		t.Errorf("expected exit status 1, got %d", code)
+	}
+}
+
+func TestJob_MustExecute(t *testing.T) {
+	codes := []int{}
+	osExit = func(code int) {
+		codes = append(codes, code)
+	}
+	defer func() { osExit = os.Exit }()
+
+	job := Job{
+		Run: func() error {
+			return nil
+		},
+		Close: func() error {
+			return nil
+		},
+	}
+	job.MustExecute()
+	if len(codes) != 0 {
+		t.Errorf("expected no exit on success, got %v", codes)
+	}
+
+	job.Run = func() error {
+		return errors.New("some error")
+	}
+	// error expected here
+	job.MustExecute()
+	if len(codes) != 1 || codes[0] != 1 {
+		t.Errorf("expected exit status 1, got %v", codes)
+	}
+}
+
+func Test_exitCode(t *testing.T) {
+	if code := exitCode(nil); code != 0 {
+		t.Errorf("expected exit status 0, got %d", code)
+	}
+	if code := exitCode(errors.New("some error")); code != 1 {
+		t.Errorf("expected exit status 1, got %d", code)
+	}
+}
+
+func TestJob_runMain(t *testing.T) {
+	var stderr bytes.Buffer
+	closed := false
+	job := Job{
+		Close: func() error {
+			closed = true
+			return nil
+		},
+	}
+
+	code := job.runMain(func() error {
+		return nil
+	}, &stderr)
+	if code != 0 {
+		t.Errorf("expected exit status 0, got %d", code)
+	}
+	if !closed {
+		t.Error("expected Close to run after appMain returned")
+	}
+}
+
+func TestJob_runMainFinally(t *testing.T) {
+	var stderr bytes.Buffer
+	var calls []string
+	job := Job{
+		Close: func() error {
+			calls = append(calls, "close")
+			return nil
+		},
+		Finally: func() {
+			calls = append(calls, "finally")
+		},
+	}
+
+	job.runMain(func() error {
+		return nil
+	}, &stderr)
+	if want := []string{"close", "finally"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("expected %v, got %v", want, calls)
+	}
+}
+
+func TestJob_runMainAfterExecuteFinally(t *testing.T) {
+	var stderr bytes.Buffer
+	finals := 0
+	job := Job{
+		Run: func() error {
+			return nil
+		},
+		Close: func() error {
+			return nil
+		},
+		Finally: func() {
+			finals++
+		},
+	}
+
+	job.runMain(job.Execute, &stderr)
+	if finals != 1 {
+		t.Errorf("expected Finally to run once, ran %d times", finals)
+	}
+}
+
+func TestJob_runMainCloseErrors(t *testing.T) {
+	var stderr bytes.Buffer
+	job := Job{
+		Close: func() error {
+			return errors.New("some error")
+		},
+	}
+
+	code := job.runMain(func() error {
+		return nil
+	}, &stderr)
+	if code != 1 {
+		t.Errorf("expected exit status 1, got %d", code)
+	}
+}
+
+func TestJob_runMainAfterExecute(t *testing.T) {
+	var stderr bytes.Buffer
+	closes := 0
+	job := Job{
+		Run: func() error {
+			return nil
+		},
+		Close: func() error {
+			closes++
+			return nil
+		},
+	}
+
+	code := job.runMain(job.Execute, &stderr)
+	if code != 0 {
+		t.Errorf("expected exit status 0, got %d", code)
+	}
+	if closes != 1 {
+		t.Errorf("expected Close to run once, ran %d times", closes)
+	}
+}
+
+func TestJob_closeOnceRaces(t *testing.T) {
+	var closes int32
+	job := Job{
+		RunCtx: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		Close: func() error {
+			atomic.AddInt32(&closes, 1)
+			return nil
+		},
+	}
+	job.RunWithClose()
+
+	// Race the lifecycle's close path against direct close calls.
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			job.SignalToClose()
+			job.closeOnce()
+		}()
+	}
+	wg.Wait()
+	job.Wait()
+
+	if n := atomic.LoadInt32(&closes); n != 1 {
+		t.Errorf("expected Close to run once, ran %d times", n)
+	}
+}
+
+func TestJob_closeOnceInFlight(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	job := Job{
+		RunCtx: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		Close: func() error {
+			close(started)
+			<-release
+			return nil
+		},
+	}
+	job.RunWithClose()
+	job.SignalToClose()
+	<-started
+
+	returned := make(chan struct{})
+	go func() {
+		job.closeOnce()
+		close(returned)
+	}()
+
+	select {
+	case <-returned:
+	case <-time.After(time.Second * 2):
+		t.Fatal("expected closeOnce not to wait for the Close in flight")
+	}
+	close(release)
+	job.Wait()
+}
+
+func TestJob_runMainCloseTimeout(t *testing.T) {
+	var stderr bytes.Buffer
+	job := Job{
+		Run: func() error {
+			return nil
+		},
+		Close: func() error {
+			<-time.After(time.Second * 5)
+			return nil
+		},
+		CloseTimeout: time.Millisecond * 100,
+	}
+
+	done := make(chan int, 1)
+	go func() {
+		done <- job.runMain(job.Execute, &stderr)
+	}()
+
+	select {
+	case code := <-done:
+		if code != 1 {
+			t.Errorf("expected exit status 1, got %d", code)
+		}
+	case <-time.After(time.Second * 2):
+		t.Fatal("expected runMain not to wait for a hung Close")
+	}
+}
+
+func TestJob_runMainStrictClose(t *testing.T) {
+	var stderr bytes.Buffer
+	job := Job{
+		Run: func() error {
+			return nil
+		},
+		Close: func() error {
+			return nil
+		},
+		StrictClose: true,
+	}
+
+	// error expected here
+	if code := job.runMain(job.Execute, &stderr); code != 1 {
+		t.Errorf("expected exit status 1, got %d", code)
+	}
+	if stderr.String() != ErrCloseRepeated.Error()+"\n" {
+		t.Errorf("expected ErrCloseRepeated on stderr, got %q", stderr.String())
+	}
+}
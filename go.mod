@@ -1,3 +1,3 @@
 module github.com/jharshman/async
 
-go 1.14
+go 1.20
@@ -0,0 +1,113 @@
+package async
+
+import (
+	"fmt"
+	"sync"
+)
+
+// hasDependencies reports whether any member of the group has DependsOn
+// set.
+func (g *JobGroup) hasDependencies() bool {
+	for _, j := range g.jobs {
+		if len(j.DependsOn) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// position returns the position of j in the group, or -1.
+func (g *JobGroup) position(j *Job) int {
+	for i, c := range g.jobs {
+		if c == j {
+			return i
+		}
+	}
+	return -1
+}
+
+// startOrder returns the positions of the group's members in the order
+// they are to be started: every job after the jobs it depends on, and
+// otherwise in the order they were added. It returns an error if a
+// dependency is not a member or the dependencies form a cycle.
+func (g *JobGroup) startOrder() ([]int, error) {
+	if !g.hasDependencies() {
+		order := make([]int, len(g.jobs))
+		for i := range order {
+			order[i] = i
+		}
+		return order, nil
+	}
+	if g.Ordered || g.CloseConcurrency > 0 {
+		return nil, fmt.Errorf("DependsOn cannot be combined with Ordered or CloseConcurrency")
+	}
+
+	for i, j := range g.jobs {
+		for _, d := range j.DependsOn {
+			if g.position(d) < 0 {
+				return nil, fmt.Errorf("job %d in group: dependency is not a member of the group", i)
+			}
+		}
+	}
+
+	placed := make([]bool, len(g.jobs))
+	order := make([]int, 0, len(g.jobs))
+	for len(order) < len(g.jobs) {
+		next := -1
+		for i, j := range g.jobs {
+			if !placed[i] && g.depsPlaced(j, placed) {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			var cycle []int
+			for i := range g.jobs {
+				if !placed[i] {
+					cycle = append(cycle, i)
+				}
+			}
+			return nil, fmt.Errorf("dependency cycle among jobs %v in group", cycle)
+		}
+		placed[next] = true
+		order = append(order, next)
+	}
+	return order, nil
+}
+
+// depsPlaced reports whether every job j depends on is placed.
+func (g *JobGroup) depsPlaced(j *Job, placed []bool) bool {
+	for _, d := range j.DependsOn {
+		if !placed[g.position(d)] {
+			return false
+		}
+	}
+	return true
+}
+
+// closeGraph closes each of started once every started member that
+// depends on it has finished closing, so dependencies outlive their
+// dependents. It returns once every member has been closed.
+func closeGraph(started []*member) {
+	var wg sync.WaitGroup
+	for _, m := range started {
+		var dependents []*member
+		for _, c := range started {
+			for _, d := range c.job.DependsOn {
+				if d == m.job {
+					dependents = append(dependents, c)
+				}
+			}
+		}
+
+		wg.Add(1)
+		go func(m *member, dependents []*member) {
+			defer wg.Done()
+			for _, c := range dependents {
+				<-c.done
+			}
+			m.close()
+		}(m, dependents)
+	}
+	wg.Wait()
+}
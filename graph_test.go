@@ -0,0 +1,196 @@
+package async_test
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/jharshman/async"
+)
+
+func TestJobGroup_RunDependsOn(t *testing.T) {
+	var mu sync.Mutex
+	var events []string
+	record := func(e string) {
+		mu.Lock()
+		events = append(events, e)
+		mu.Unlock()
+	}
+	job := func(name string) *async.Job {
+		stopped := make(chan struct{})
+		return &async.Job{
+			RunReady: func(ready func()) error {
+				record("start " + name)
+				// dependents wait for this, not for the start
+				<-time.After(time.Millisecond * 20)
+				ready()
+				<-stopped
+				return nil
+			},
+			Close: func() error {
+				record("close " + name)
+				close(stopped)
+				return nil
+			},
+		}
+	}
+	cache, api, webhooks := job("cache"), job("api"), job("webhooks")
+	api.DependsOn = []*async.Job{cache}
+	webhooks.DependsOn = []*async.Job{api, cache}
+
+	started := make(chan struct{})
+	webhooks.OnStart = func() {
+		close(started)
+	}
+	g := &async.JobGroup{
+		Signals: []os.Signal{syscall.SIGUSR2},
+	}
+	g.Add(webhooks)
+	g.Add(api)
+	g.Add(cache)
+
+	result := make(chan error, 1)
+	go func() { result <- g.Run() }()
+	<-started
+	<-webhooks.Ready()
+
+	if err := async.ShutdownAll(g); err != nil {
+		t.Error(err)
+	}
+	if err := <-result; err != nil {
+		t.Error(err)
+	}
+
+	want := []string{
+		"start cache", "start api", "start webhooks",
+		"close webhooks", "close api", "close cache",
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("expected %v, got %v", want, events)
+	}
+}
+
+func TestJobGroup_RunDependsOnCycle(t *testing.T) {
+	var ran bool
+	job := func() *async.Job {
+		return &async.Job{
+			Run: func() error {
+				ran = true
+				return nil
+			},
+			Close: func() error {
+				return nil
+			},
+		}
+	}
+	first, second := job(), job()
+	first.DependsOn = []*async.Job{second}
+	second.DependsOn = []*async.Job{first}
+	g := async.JobGroup{}
+	g.Add(first)
+	g.Add(second)
+
+	// error expected here
+	err := g.Run()
+	if err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("expected a dependency cycle error, got %v", err)
+	}
+	if ran {
+		t.Error("expected no job to be started")
+	}
+}
+
+func TestJobGroup_RunDependsOnStopped(t *testing.T) {
+	errFailed := errors.New("some error")
+	dep := &async.Job{
+		RunReady: func(ready func()) error {
+			return errFailed
+		},
+		Close: func() error {
+			return nil
+		},
+	}
+	var ran bool
+	dependent := &async.Job{
+		Run: func() error {
+			ran = true
+			return nil
+		},
+		Close: func() error {
+			return nil
+		},
+		DependsOn: []*async.Job{dep},
+	}
+	g := async.JobGroup{}
+	g.Add(dependent)
+	g.Add(dep)
+
+	// error expected here
+	err := g.Run()
+	if !errors.Is(err, errFailed) || !strings.Contains(err.Error(), "before it was ready") {
+		t.Errorf("expected the dependency's failure, got %v", err)
+	}
+	if ran {
+		t.Error("expected the dependent not to be started")
+	}
+}
+
+func TestJobGroup_RunDependsOnFailed(t *testing.T) {
+	errFailed := errors.New("some error")
+	failing := &async.Job{
+		Run: func() error {
+			return errFailed
+		},
+		Close: func() error {
+			return nil
+		},
+	}
+	stopped := make(chan struct{})
+	dep := &async.Job{
+		RunReady: func(ready func()) error {
+			// never ready
+			<-stopped
+			return nil
+		},
+		Close: func() error {
+			close(stopped)
+			return nil
+		},
+	}
+	var ran bool
+	dependent := &async.Job{
+		Run: func() error {
+			ran = true
+			return nil
+		},
+		Close: func() error {
+			return nil
+		},
+		DependsOn: []*async.Job{dep},
+	}
+	g := async.JobGroup{}
+	g.Add(failing)
+	g.Add(dep)
+	g.Add(dependent)
+
+	result := make(chan error, 1)
+	go func() { result <- g.Run() }()
+
+	select {
+	case err := <-result:
+		// error expected here
+		if !errors.Is(err, errFailed) {
+			t.Errorf("expected the failing member's error, got %v", err)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("expected the group to stop waiting on the dependency once a member failed")
+	}
+	if ran {
+		t.Error("expected the dependent not to be started")
+	}
+}
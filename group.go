@@ -0,0 +1,539 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// JobGroup runs several jobs together. All members are started at once
+// and all are closed when one of the group's signals is received or when
+// any member's Run fails.
+//
+// Members are started with RunWithClose, so the group listens for
+// signals once on behalf of all of them and each member's own Signals
+// are not used. A member's TelemetryFlush is called once the whole
+// group has closed. The fields that only Execute acts on, MinUptime,
+// OnClosingSoon, MaxRuntime, StartupTimeout, MinFreeDiskBytes, Next,
+// SignalHandlers, ReloadSignals and ShouldRun, are rejected by Run.
+//
+// Members are started in the order they were added, unless Job.DependsOn
+// says otherwise: a member is then started once the members it depends
+// on are ready, and closed before any of them. Run returns an error
+// before starting anything if the dependencies form a cycle.
+type JobGroup struct {
+	// Signals is a slice of os.Signal to notify on.
+	// Defaults to the same signals as Job.Signals.
+	Signals []os.Signal
+
+	// NoSignals turns off the group's own signal handling, for a group
+	// that is shut down from outside, as by ShutdownAllOnSignal. Signals
+	// is then ignored.
+	NoSignals bool
+
+	// Ordered closes members one at a time in the reverse of the order
+	// they were added, waiting for each to finish closing before closing
+	// the next. A member that fails to close does not stop the sequence;
+	// its error is returned along with the rest. When false, all members
+	// are closed concurrently.
+	Ordered bool
+
+	// CloseConcurrency, if positive, limits how many members are closing
+	// at once. Closes are started in order as earlier ones finish: the
+	// reverse of the order members were added when Ordered is set, and
+	// the order they were added otherwise. Ordered alone behaves as a
+	// CloseConcurrency of 1.
+	CloseConcurrency int
+
+	// ShutdownTimeout, if positive, bounds the whole close sequence of the
+	// group, counted from the signal or failure that starts it. Members
+	// that have not finished closing by then are left behind: Run returns
+	// ErrShutdownTimeout naming their positions, along with the errors of
+	// the members that did finish. Each member's own CloseTimeout still
+	// applies within it.
+	ShutdownTimeout time.Duration
+
+	// SharedRestartBudget, if positive, caps the restarts of all members
+	// added with AddSupervisor taken together at that many within any
+	// SharedRestartWindow, or over the whole of Run if the window is zero.
+	// Once it is spent, a member whose Run fails again is not restarted;
+	// its error ends it and shuts the group down.
+	SharedRestartBudget int
+	SharedRestartWindow time.Duration
+
+	jobs []*Job
+
+	// supervisors holds the Supervisor of each member added with
+	// AddSupervisor.
+	supervisors map[*Job]*Supervisor
+
+	// mu guards run.
+	mu sync.Mutex
+
+	// run is the latest call to Run.
+	run *groupRun
+}
+
+// groupRun tracks a single call to JobGroup.Run so that it can be shut
+// down from outside.
+type groupRun struct {
+	stop chan struct{}
+	once sync.Once
+
+	// done is closed once Run has returned err.
+	done chan struct{}
+	err  error
+
+	// members holds, under the group's mu, the members started so far.
+	members []*member
+}
+
+// shutdown closes the group's members as a signal would and waits for
+// Run to return, returning its error.
+func (r *groupRun) shutdown() error {
+	r.once.Do(func() { close(r.stop) })
+	<-r.done
+	return r.err
+}
+
+// member tracks a single job while its group runs.
+type member struct {
+	job *Job
+	// pos is the position of the job in the group.
+	pos   int
+	sig   chan int
+	ready <-chan struct{}
+	errs  []error
+	done  chan struct{}
+}
+
+// Add adds j to the group. Jobs must be added before Run is called.
+func (g *JobGroup) Add(j *Job) {
+	g.jobs = append(g.jobs, j)
+}
+
+// AddSupervisor adds the job supervised by s to the group. Its Run is
+// restarted on failure according to s, within SharedRestartBudget.
+func (g *JobGroup) AddSupervisor(s *Supervisor) {
+	if g.supervisors == nil {
+		g.supervisors = map[*Job]*Supervisor{}
+	}
+	g.supervisors[s.Job] = s
+	g.Add(s.Job)
+}
+
+// Run starts every member and blocks until all of them have closed.
+// The errors of all members are returned joined, each wrapped with the
+// position of the job in the group.
+func (g *JobGroup) Run() error {
+	return g.runGroup(nil)
+}
+
+// Execute is Run, so that a JobGroup is a Runnable.
+func (g *JobGroup) Execute() error {
+	return g.runGroup(nil)
+}
+
+// ExecuteContext is Run, but the group is also closed as by a signal
+// when ctx is done.
+func (g *JobGroup) ExecuteContext(ctx context.Context) error {
+	return g.runGroup(ctx.Done())
+}
+
+// runGroup implements Run. The group closes when trigger is closed, as
+// when one of its signals is received.
+func (g *JobGroup) runGroup(trigger <-chan struct{}) (result error) {
+	r := &groupRun{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	g.mu.Lock()
+	g.run = r
+	g.mu.Unlock()
+	defer func() {
+		r.err = result
+		close(r.done)
+	}()
+
+	for i, j := range g.jobs {
+		if e := j.Validate(); e != nil {
+			return fmt.Errorf("job %d in group: %w", i, e)
+		}
+		if e := j.validateMember(); e != nil {
+			return fmt.Errorf("job %d in group: %w", i, e)
+		}
+	}
+	order, e := g.startOrder()
+	if e != nil {
+		return e
+	}
+
+	closeChan := make(chan os.Signal, 1)
+	if !g.NoSignals {
+		signals := g.Signals
+		if len(signals) == 0 {
+			signals = defaultSignals
+		}
+		notifySignals(closeChan, signals...)
+		defer stopSignals(closeChan)
+	}
+
+	var shared *restartBudget
+	if g.SharedRestartBudget > 0 {
+		shared = &restartBudget{
+			max:    g.SharedRestartBudget,
+			window: g.SharedRestartWindow,
+		}
+	}
+
+	var once sync.Once
+	failed := make(chan struct{})
+	fail := func() {
+		once.Do(func() { close(failed) })
+	}
+
+	// members is indexed by position in the group, started is in the
+	// order the members were started. A member is left nil if the
+	// group shuts down before it is started.
+	var (
+		members     = make([]*member, len(g.jobs))
+		started     []*member
+		interrupted bool
+		startErr    error
+	)
+START:
+	for _, i := range order {
+		j := g.jobs[i]
+		for _, d := range j.DependsOn {
+			dep := members[g.position(d)]
+			stopped := false
+			select {
+			case <-dep.ready:
+				continue
+			case <-dep.done:
+				stopped = true
+			case s := <-closeChan:
+				interrupted = true
+				signalMembers(started, s)
+			case <-r.stop:
+				interrupted = true
+			case <-trigger:
+				interrupted = true
+			case <-failed:
+				// The dependency itself may be the member that failed.
+				stopped = dep.failed()
+				interrupted = !stopped
+			}
+			if stopped {
+				startErr = fmt.Errorf("job %d in group: dependency job %d stopped before it was ready", i, dep.pos)
+				fail()
+			}
+			break START
+		}
+
+		opts := runOptions{member: true}
+		if s := g.supervisors[j]; s != nil {
+			opts.restart = s.newRestarter(shared).restart
+		}
+		sig, ack, err, e := j.start(opts)
+		if e != nil {
+			// Close the members already started before giving up.
+			for _, m := range started {
+				m.close()
+				<-m.done
+			}
+			return fmt.Errorf("job %d in group: %w", i, e)
+		}
+		m := &member{
+			job:   j,
+			pos:   i,
+			sig:   sig,
+			ready: j.Ready(),
+			done:  make(chan struct{}),
+		}
+		members[i] = m
+		started = append(started, m)
+		g.mu.Lock()
+		r.members = started
+		g.mu.Unlock()
+		go m.watch(ack, err, fail)
+	}
+
+	allDone := make(chan struct{})
+	go func() {
+		for _, m := range started {
+			<-m.done
+		}
+		close(allDone)
+	}()
+
+	if !interrupted && startErr == nil {
+		select {
+		case s := <-closeChan:
+			signalMembers(started, s)
+		case <-r.stop:
+		case <-trigger:
+		case <-failed:
+		case <-allDone:
+		}
+	}
+	go func() {
+		switch {
+		case g.hasDependencies():
+			closeGraph(started)
+		case g.Ordered || g.CloseConcurrency > 0:
+			g.closeLimited(started)
+		}
+		for _, m := range started {
+			m.close()
+		}
+	}()
+
+	var timeout <-chan time.Time
+	if g.ShutdownTimeout > 0 {
+		t := time.NewTimer(g.ShutdownTimeout)
+		defer t.Stop()
+		timeout = t.C
+	}
+	var unfinished []int
+	select {
+	case <-allDone:
+	case <-timeout:
+		for i, m := range members {
+			if m == nil {
+				continue
+			}
+			select {
+			case <-m.done:
+			default:
+				unfinished = append(unfinished, i)
+			}
+		}
+	}
+
+	var errs []error
+	if startErr != nil {
+		errs = append(errs, startErr)
+	}
+	for i, m := range members {
+		if m == nil {
+			continue
+		}
+		var memberErrs []error
+		select {
+		case <-m.done:
+			memberErrs = m.errs
+		default:
+			// Its errors are still being collected.
+		}
+		if m.job.TelemetryFlush != nil {
+			if e := m.job.flushTelemetry(); e != nil {
+				memberErrs = append(memberErrs, e)
+			}
+		}
+		for _, e := range memberErrs {
+			errs = append(errs, fmt.Errorf("job %d in group: %w", i, e))
+		}
+	}
+	if len(unfinished) > 0 {
+		errs = append(errs, fmt.Errorf("%w: jobs %v did not finish closing", ErrShutdownTimeout, unfinished))
+	}
+	return errors.Join(errs...)
+}
+
+// closeLimited closes members in the group's close order, with no more
+// than CloseConcurrency of them closing at once.
+func (g *JobGroup) closeLimited(members []*member) {
+	limit := g.CloseConcurrency
+	if limit <= 0 {
+		limit = 1
+	}
+	order := make([]*member, len(members))
+	copy(order, members)
+	if g.Ordered {
+		for i, j := 0, len(order)-1; i < j; i, j = i+1, j-1 {
+			order[i], order[j] = order[j], order[i]
+		}
+	}
+
+	slots := make(chan struct{}, limit)
+	for _, m := range order {
+		slots <- struct{}{}
+		m.close()
+		go func(m *member) {
+			<-m.done
+			<-slots
+		}(m)
+	}
+}
+
+// validateMember rejects the fields that only Execute acts on, which a
+// group would otherwise silently ignore.
+func (j *Job) validateMember() error {
+	switch {
+	case j.MinUptime > 0 || j.OnClosingSoon != nil:
+		return fmt.Errorf("MinUptime not supported in a group")
+	case j.MinFreeDiskBytes > 0:
+		return fmt.Errorf("MinFreeDiskBytes not supported in a group")
+	case j.MaxRuntime > 0:
+		return fmt.Errorf("MaxRuntime not supported in a group")
+	case j.Next != nil:
+		return fmt.Errorf("Next not supported in a group")
+	case len(j.SignalHandlers) > 0:
+		return fmt.Errorf("SignalHandlers not supported in a group")
+	case len(j.ReloadSignals) > 0:
+		return fmt.Errorf("ReloadSignals not supported in a group")
+	case j.ShouldRun != nil:
+		return fmt.Errorf("ShouldRun not supported in a group")
+	case j.StartupTimeout > 0:
+		return fmt.Errorf("StartupTimeout not supported in a group")
+	}
+	return nil
+}
+
+// signalMembers calls Job.OnSignal on each of started with s, the
+// signal that closed the group.
+func signalMembers(started []*member, s os.Signal) {
+	for _, m := range started {
+		if m.job.OnSignal != nil {
+			m.job.OnSignal(s)
+		}
+	}
+}
+
+// watch collects the member's errors until its close path has finished,
+// calling fail on each error so the group starts shutting down.
+func (m *member) watch(ack chan int, err chan error, fail func()) {
+	defer close(m.done)
+	for {
+		select {
+		case e := <-err:
+			m.errs = append(m.errs, e)
+			fail()
+			if errors.Is(e, ErrCloseTimeout) {
+				// Finally is still to run, and the member's
+				// TelemetryFlush must follow it.
+				<-m.job.Done()
+				return
+			}
+		case <-ack:
+			for {
+				select {
+				case e := <-err:
+					m.errs = append(m.errs, e)
+					fail()
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// failed reports whether the member's latest run has reported an error.
+func (m *member) failed() bool {
+	m.job.mu.Lock()
+	l := m.job.l
+	m.job.mu.Unlock()
+	return l.err() != nil
+}
+
+// close triggers the member's close path if it is not already pending.
+func (m *member) close() {
+	signalClose(m.sig)
+}
+
+// CloseOne closes the member j of the running group and waits for it to
+// finish closing, leaving the other members running. It returns the
+// member's errors, which are also returned by Run, and so shut the group
+// down as any member's errors do. It is an error if j is not a member,
+// the group is not running, or j has already stopped.
+func (g *JobGroup) CloseOne(j *Job) error {
+	g.mu.Lock()
+	r := g.run
+	var members []*member
+	if r != nil {
+		members = r.members
+	}
+	g.mu.Unlock()
+	if r == nil || members == nil {
+		return fmt.Errorf("group not running")
+	}
+	select {
+	case <-r.done:
+		return fmt.Errorf("group not running")
+	default:
+	}
+
+	var m *member
+	for _, c := range members {
+		if c.job == j {
+			m = c
+		}
+	}
+	if m == nil {
+		return fmt.Errorf("job is not a member of the group")
+	}
+
+	select {
+	case <-m.done:
+		return fmt.Errorf("job already stopped")
+	default:
+	}
+	m.close()
+	<-m.done
+	return errors.Join(m.errs...)
+}
+
+// ShutdownAll shuts down each running group in the given order, waiting
+// for one group's Run to return before closing the next. It returns the
+// errors of all groups joined, each wrapped with the position of the
+// group in order. Groups that have not been run are skipped. A group
+// that handles signals itself closes on the signal regardless of this
+// order; set NoSignals to leave the order to ShutdownAll.
+func ShutdownAll(order ...*JobGroup) error {
+	var errs []error
+	for i, g := range order {
+		g.mu.Lock()
+		r := g.run
+		g.mu.Unlock()
+		if r == nil {
+			continue
+		}
+		if e := r.shutdown(); e != nil {
+			errs = append(errs, fmt.Errorf("group %d: %w", i, e))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ShutdownAllOnSignal blocks until one of signals is received, or one of
+// the same signals as Job.Signals defaults to if none are given, and
+// then calls ShutdownAll. The groups should set NoSignals: a group that
+// handles the signal itself starts closing as soon as it is received,
+// whatever its place in order.
+func ShutdownAllOnSignal(signals []os.Signal, order ...*JobGroup) error {
+	if len(signals) == 0 {
+		signals = defaultSignals
+	}
+	c := make(chan os.Signal, 1)
+	notifySignals(c, signals...)
+	defer stopSignals(c)
+	<-c
+	return ShutdownAll(order...)
+}
+
+// ExecuteAll runs jobs together as an unordered JobGroup with the default
+// signals: a single signal handler closes every job, as does the failure
+// of any one of them. It blocks until every job has closed and returns
+// their errors joined.
+func ExecuteAll(jobs ...*Job) error {
+	var g JobGroup
+	for _, j := range jobs {
+		g.Add(j)
+	}
+	return g.Run()
+}
@@ -0,0 +1,563 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/jharshman/async"
+)
+
+// blockingJob returns a job whose Run blocks until Close is called.
+// closed is set once Close has run.
+func blockingJob(closed *bool) *async.Job {
+	stopped := make(chan struct{})
+	return &async.Job{
+		Run: func() error {
+			<-stopped
+			return nil
+		},
+		Close: func() error {
+			*closed = true
+			close(stopped)
+			return nil
+		},
+	}
+}
+
+func TestJobGroup_Run(t *testing.T) {
+	var first, second bool
+	g := async.JobGroup{
+		Signals: []os.Signal{syscall.SIGUSR1},
+	}
+	g.Add(blockingJob(&first))
+	g.Add(blockingJob(&second))
+
+	go func() {
+		<-time.After(time.Millisecond * 100)
+		syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	}()
+
+	err := g.Run()
+	if err != nil {
+		t.Error(err)
+	}
+	if !first || !second {
+		t.Error("expected every member to be closed")
+	}
+}
+
+func TestRunnable_ExecuteContext(t *testing.T) {
+	var job, first, second, supervised bool
+	g := &async.JobGroup{}
+	g.Add(blockingJob(&first))
+	g.Add(blockingJob(&second))
+	runners := []async.Runnable{
+		blockingJob(&job),
+		g,
+		&async.Supervisor{Job: blockingJob(&supervised)},
+	}
+
+	for i, r := range runners {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+		if err := r.ExecuteContext(ctx); err != nil {
+			t.Errorf("runner %d: %v", i, err)
+		}
+		cancel()
+	}
+	if !job || !first || !second || !supervised {
+		t.Error("expected every runner to be closed once its context was done")
+	}
+}
+
+func TestJobGroup_RunWithErrors(t *testing.T) {
+	var closed bool
+	g := async.JobGroup{}
+	g.Add(blockingJob(&closed))
+	g.Add(&async.Job{
+		Run: func() error {
+			return errors.New("some error")
+		},
+		Close: func() error {
+			return nil
+		},
+	})
+
+	// error expected here
+	err := g.Run()
+	if err == nil || err.Error() != "job 1 in group: some error" {
+		t.Errorf("expected error from job 1, got %v", err)
+	}
+	if !closed {
+		t.Error("expected the other member to be closed")
+	}
+}
+
+func TestJobGroup_RunShutdownTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	var closed bool
+	g := async.JobGroup{
+		ShutdownTimeout: time.Millisecond * 100,
+	}
+	g.Add(blockingJob(&closed))
+	g.Add(&async.Job{
+		Run: func() error {
+			<-release
+			return nil
+		},
+		Close: func() error {
+			<-release
+			return nil
+		},
+	})
+	g.Add(&async.Job{
+		Run: func() error {
+			return errors.New("some error")
+		},
+		Close: func() error {
+			return nil
+		},
+	})
+
+	// error expected here
+	err := g.Run()
+	if !errors.Is(err, async.ErrShutdownTimeout) {
+		t.Errorf("expected %v, got %v", async.ErrShutdownTimeout, err)
+	}
+	want := "job 2 in group: some error\n" + async.ErrShutdownTimeout.Error() + ": jobs [1] did not finish closing"
+	if err == nil || err.Error() != want {
+		t.Errorf("expected %q, got %q", want, err)
+	}
+	if !closed {
+		t.Error("expected the other member to be closed")
+	}
+}
+
+func TestJobGroup_RunNotValid(t *testing.T) {
+	g := async.JobGroup{}
+	g.Add(&async.Job{})
+
+	// error expected here
+	err := g.Run()
+	if err == nil {
+		t.Error(err)
+	}
+}
+
+func TestJobGroup_RunExecuteOnly(t *testing.T) {
+	var closed bool
+	job := blockingJob(&closed)
+	job.MinUptime = time.Second
+	g := async.JobGroup{}
+	g.Add(job)
+
+	// error expected here
+	err := g.Run()
+	if err == nil {
+		t.Error(err)
+	}
+}
+
+func TestJobGroup_RunStartupTimeout(t *testing.T) {
+	var closed bool
+	job := blockingJob(&closed)
+	job.StartupTimeout = time.Second
+	g := async.JobGroup{}
+	g.Add(job)
+
+	// error expected here
+	err := g.Run()
+	if err == nil || !strings.Contains(err.Error(), "StartupTimeout") {
+		t.Errorf("expected StartupTimeout to be rejected, got %v", err)
+	}
+}
+
+func TestJobGroup_RunShouldRun(t *testing.T) {
+	var closed bool
+	job := blockingJob(&closed)
+	job.ShouldRun = func() bool {
+		return false
+	}
+	g := async.JobGroup{}
+	g.Add(job)
+
+	// error expected here
+	err := g.Run()
+	if err == nil || !strings.Contains(err.Error(), "ShouldRun") {
+		t.Errorf("expected ShouldRun to be rejected, got %v", err)
+	}
+}
+
+func TestJobGroup_RunTelemetryFlush(t *testing.T) {
+	var closed, flushed bool
+	job := blockingJob(&closed)
+	job.TelemetryFlush = func(ctx context.Context) error {
+		if !closed {
+			t.Error("expected TelemetryFlush to follow Close")
+		}
+		flushed = true
+		return nil
+	}
+	g := async.JobGroup{
+		Signals: []os.Signal{syscall.SIGUSR1},
+	}
+	g.Add(job)
+
+	go func() {
+		<-time.After(time.Millisecond * 100)
+		syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	}()
+
+	err := g.Run()
+	if err != nil {
+		t.Error(err)
+	}
+	if !flushed {
+		t.Error("expected TelemetryFlush to be called")
+	}
+}
+
+func TestJobGroup_RunTelemetryFlushCloseTimeout(t *testing.T) {
+	var mu sync.Mutex
+	var events []string
+	release := make(chan struct{})
+	defer close(release)
+	var g async.JobGroup
+	g.Add(&async.Job{
+		Run: func() error {
+			return nil
+		},
+		Close: func() error {
+			<-release
+			return nil
+		},
+		CloseTimeout: time.Millisecond * 50,
+		Finally: func() {
+			<-time.After(time.Millisecond * 100)
+			mu.Lock()
+			events = append(events, "finally")
+			mu.Unlock()
+		},
+		TelemetryFlush: func(ctx context.Context) error {
+			mu.Lock()
+			events = append(events, "flush")
+			mu.Unlock()
+			return nil
+		},
+	})
+
+	// error expected here
+	err := g.Run()
+	if !errors.Is(err, async.ErrCloseTimeout) {
+		t.Errorf("expected ErrCloseTimeout, got %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !reflect.DeepEqual(events, []string{"finally", "flush"}) {
+		t.Errorf("expected flush to follow Finally, got %v", events)
+	}
+}
+func TestJobGroup_RunOrdered(t *testing.T) {
+	var mu sync.Mutex
+	var events []string
+	record := func(e string) {
+		mu.Lock()
+		events = append(events, e)
+		mu.Unlock()
+	}
+
+	g := async.JobGroup{
+		Ordered: true,
+	}
+	for _, name := range []string{"db", "cache", "http"} {
+		name := name
+		stopped := make(chan struct{})
+		g.Add(&async.Job{
+			Run: func() error {
+				<-stopped
+				return nil
+			},
+			Close: func() error {
+				record(name + " closing")
+				<-time.After(time.Millisecond * 20)
+				record(name + " closed")
+				close(stopped)
+				if name == "cache" {
+					return errors.New("some error")
+				}
+				return nil
+			},
+		})
+	}
+	g.Add(&async.Job{
+		Run: func() error {
+			<-time.After(time.Millisecond * 100)
+			return errors.New("trigger shutdown")
+		},
+		Close: func() error {
+			return nil
+		},
+	})
+
+	// error expected here
+	err := g.Run()
+	if err == nil {
+		t.Error(err)
+	}
+
+	want := []string{
+		"http closing", "http closed",
+		"cache closing", "cache closed",
+		"db closing", "db closed",
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("expected close order %v, got %v", want, events)
+	}
+}
+
+func TestJobGroup_RunCloseConcurrency(t *testing.T) {
+	var mu sync.Mutex
+	active, peak := 0, 0
+	g := &async.JobGroup{
+		Signals:          []os.Signal{syscall.SIGUSR2},
+		CloseConcurrency: 2,
+	}
+	started := make(chan struct{}, 6)
+	for i := 0; i < 6; i++ {
+		stopped := make(chan struct{})
+		g.Add(&async.Job{
+			Run: func() error {
+				<-stopped
+				return nil
+			},
+			Close: func() error {
+				mu.Lock()
+				active++
+				if active > peak {
+					peak = active
+				}
+				mu.Unlock()
+				<-time.After(time.Millisecond * 20)
+				mu.Lock()
+				active--
+				mu.Unlock()
+				close(stopped)
+				return nil
+			},
+			OnStart: func() {
+				started <- struct{}{}
+			},
+		})
+	}
+
+	result := make(chan error, 1)
+	go func() { result <- g.Run() }()
+	for i := 0; i < 6; i++ {
+		<-started
+	}
+
+	if err := async.ShutdownAll(g); err != nil {
+		t.Error(err)
+	}
+	if err := <-result; err != nil {
+		t.Error(err)
+	}
+	if peak != 2 {
+		t.Errorf("expected at most 2 concurrent closes, got %d", peak)
+	}
+}
+
+func TestJobGroup_RunSharedRestartBudget(t *testing.T) {
+	var runs int32
+	g := async.JobGroup{
+		Signals:             []os.Signal{syscall.SIGUSR2},
+		SharedRestartBudget: 3,
+	}
+	for i := 0; i < 2; i++ {
+		g.AddSupervisor(&async.Supervisor{
+			Job: &async.Job{
+				Run: func() error {
+					atomic.AddInt32(&runs, 1)
+					return errors.New("some error")
+				},
+				Close: func() error {
+					return nil
+				},
+			},
+			MaxRestarts: 5,
+		})
+	}
+
+	// error expected here
+	err := g.Run()
+	if err == nil {
+		t.Error(err)
+	}
+
+	// each job runs once, and together they restart 3 times
+	if n := atomic.LoadInt32(&runs); n != 5 {
+		t.Errorf("expected 5 runs within the shared budget, got %d", n)
+	}
+}
+
+func TestJobGroup_RunRestartMember(t *testing.T) {
+	var closed bool
+	job := blockingJob(&closed)
+	started := make(chan struct{})
+	job.OnStart = func() {
+		close(started)
+	}
+	g := async.JobGroup{
+		NoSignals: true,
+	}
+	g.Add(job)
+
+	result := make(chan error, 1)
+	go func() { result <- g.Run() }()
+	<-started
+
+	// error expected here
+	if err := job.Restart(); err == nil {
+		t.Error("expected Restart to be rejected for a group member")
+	}
+	if closed || job.State() != async.StateRunning {
+		t.Errorf("expected the member to keep running, got %v", job.State())
+	}
+
+	if err := async.ShutdownAll(&g); err != nil {
+		t.Error(err)
+	}
+	if err := <-result; err != nil {
+		t.Error(err)
+	}
+}
+
+func TestJobGroup_CloseOne(t *testing.T) {
+	var firstClosed, secondClosed bool
+	first := blockingJob(&firstClosed)
+	second := blockingJob(&secondClosed)
+	started := make(chan struct{})
+	// members are started in order, so both have started by then
+	second.OnStart = func() {
+		close(started)
+	}
+	g := async.JobGroup{
+		Signals: []os.Signal{syscall.SIGUSR2},
+	}
+	g.Add(first)
+	g.Add(second)
+
+	// error expected here
+	if err := g.CloseOne(first); err == nil {
+		t.Error("expected an error before the group runs")
+	}
+
+	result := make(chan error, 1)
+	go func() { result <- g.Run() }()
+	<-started
+
+	if err := g.CloseOne(first); err != nil {
+		t.Error(err)
+	}
+	if !firstClosed || secondClosed {
+		t.Errorf("expected only the first member closed, got %v and %v", firstClosed, secondClosed)
+	}
+	// error expected here
+	if err := g.CloseOne(first); err == nil {
+		t.Error("expected an error closing a stopped member")
+	}
+	// error expected here
+	if err := g.CloseOne(&async.Job{}); err == nil {
+		t.Error("expected an error closing a job outside the group")
+	}
+
+	if err := g.CloseOne(second); err != nil {
+		t.Error(err)
+	}
+	if err := <-result; err != nil {
+		t.Error(err)
+	}
+}
+
+func TestShutdownAll(t *testing.T) {
+	var mu sync.Mutex
+	var closed []string
+	newGroup := func(name string) (*async.JobGroup, chan struct{}) {
+		started := make(chan struct{})
+		stopped := make(chan struct{})
+		g := &async.JobGroup{
+			NoSignals: true,
+		}
+		g.Add(&async.Job{
+			Run: func() error {
+				<-stopped
+				return nil
+			},
+			Close: func() error {
+				mu.Lock()
+				closed = append(closed, name)
+				mu.Unlock()
+				close(stopped)
+				return nil
+			},
+			OnStart: func() {
+				close(started)
+			},
+		})
+		return g, started
+	}
+	first, firstStarted := newGroup("first")
+	second, secondStarted := newGroup("second")
+
+	results := make(chan error, 2)
+	go func() { results <- first.Run() }()
+	go func() { results <- second.Run() }()
+	<-firstStarted
+	<-secondStarted
+
+	err := async.ShutdownAll(second, first)
+	if err != nil {
+		t.Error(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := <-results; err != nil {
+			t.Error(err)
+		}
+	}
+	if !reflect.DeepEqual(closed, []string{"second", "first"}) {
+		t.Errorf("expected groups to close in order, got %v", closed)
+	}
+}
+
+func TestExecuteAll(t *testing.T) {
+	var first, second bool
+	err := async.ExecuteAll(
+		blockingJob(&first),
+		blockingJob(&second),
+		&async.Job{
+			Run: func() error {
+				return errors.New("some error")
+			},
+			Close: func() error {
+				return nil
+			},
+		},
+	)
+
+	// error expected here
+	if err == nil {
+		t.Error(err)
+	}
+	if !first || !second {
+		t.Error("expected every job to be closed")
+	}
+}
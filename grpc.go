@@ -0,0 +1,54 @@
+package async
+
+import (
+	"context"
+	"net"
+)
+
+// GRPCServer is the subset of *grpc.Server used by GRPCServerJob. It is
+// declared here so that this package does not depend on grpc.
+type GRPCServer interface {
+	Serve(lis net.Listener) error
+	GracefulStop()
+	Stop()
+}
+
+// grpcErrServerStopped is the message of grpc.ErrServerStopped, which
+// Serve returns if the server was stopped before it was called.
+const grpcErrServerStopped = "grpc: the server has been stopped"
+
+// GRPCServerJob returns a Job, configured by opts, that serves s on lis.
+// grpc.ErrServerStopped from Serve is treated as a clean exit.
+//
+// Close calls GracefulStop, which waits for pending RPCs to finish. If
+// Job.CloseTimeout is set and elapses first, Stop is called to close the
+// remaining connections and the job reports ErrCloseTimeout.
+func GRPCServerJob(s GRPCServer, lis net.Listener, opts ...Option) *Job {
+	j := &Job{
+		Run: func() error {
+			e := s.Serve(lis)
+			if e != nil && e.Error() == grpcErrServerStopped {
+				return nil
+			}
+			return e
+		},
+		CloseCtx: func(ctx context.Context) error {
+			stopped := make(chan struct{})
+			go func() {
+				s.GracefulStop()
+				close(stopped)
+			}()
+			select {
+			case <-stopped:
+				return nil
+			case <-ctx.Done():
+				s.Stop()
+				return ErrCloseTimeout
+			}
+		},
+	}
+	for _, opt := range opts {
+		opt(j)
+	}
+	return j
+}
@@ -0,0 +1,110 @@
+package async_test
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jharshman/async"
+)
+
+// fakeGRPCServer behaves like *grpc.Server: Serve blocks until the
+// server is stopped, and GracefulStop waits for pending RPCs, which
+// Stop cancels.
+type fakeGRPCServer struct {
+	mu      sync.Mutex
+	pending chan struct{}
+	done    chan struct{}
+	stopped bool
+	once    sync.Once
+}
+
+func newFakeGRPCServer(pending bool) *fakeGRPCServer {
+	s := &fakeGRPCServer{
+		pending: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	if !pending {
+		close(s.pending)
+	}
+	return s
+}
+
+func (s *fakeGRPCServer) Serve(lis net.Listener) error {
+	<-s.done
+	return nil
+}
+
+func (s *fakeGRPCServer) GracefulStop() {
+	<-s.pending
+	s.once.Do(func() { close(s.done) })
+}
+
+func (s *fakeGRPCServer) Stop() {
+	s.mu.Lock()
+	s.stopped = true
+	s.mu.Unlock()
+	s.once.Do(func() { close(s.done) })
+}
+
+func TestGRPCServerJob(t *testing.T) {
+	s := newFakeGRPCServer(false)
+	job := async.GRPCServerJob(s, nil)
+
+	go func() {
+		<-time.After(time.Millisecond * 100)
+		job.SignalToClose()
+	}()
+
+	err := job.Execute()
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGRPCServerJobStopAfterTimeout(t *testing.T) {
+	s := newFakeGRPCServer(true)
+	job := async.GRPCServerJob(s, nil, async.WithCloseTimeout(time.Millisecond*100))
+
+	go func() {
+		<-time.After(time.Millisecond * 100)
+		job.SignalToClose()
+	}()
+
+	// error expected here
+	err := job.Execute()
+	if !errors.Is(err, async.ErrCloseTimeout) {
+		t.Errorf("expected ErrCloseTimeout, got %v", err)
+	}
+
+	// Stop is called from Close once the timeout has elapsed.
+	<-time.After(time.Millisecond * 100)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.stopped {
+		t.Error("expected Stop to be called after CloseTimeout")
+	}
+}
+
+func TestGRPCServerJobServerStopped(t *testing.T) {
+	job := async.GRPCServerJob(stoppedGRPCServer{}, nil)
+
+	err := job.Execute()
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+// stoppedGRPCServer has already been stopped, so Serve fails at once
+// with grpc.ErrServerStopped.
+type stoppedGRPCServer struct{}
+
+func (stoppedGRPCServer) Serve(lis net.Listener) error {
+	return errors.New("grpc: the server has been stopped")
+}
+
+func (stoppedGRPCServer) GracefulStop() {}
+
+func (stoppedGRPCServer) Stop() {}
@@ -0,0 +1,60 @@
+package async
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Handoff replaces the running job old with new, for in-process
+// blue/green upgrades. It starts new as Start does and waits up to
+// readyTimeout for it to become ready, as described on Job.RunReady;
+// zero waits indefinitely. Once new is ready, old is signaled to close,
+// and Handoff waits for it to finish closing and returns its errors, as
+// Job.Wait does.
+//
+// If new is not ready in time, or its Run returns first, new is closed
+// and old is left running. Handoff then returns ErrStartupTimeout or an
+// error saying new stopped, joined with new's errors. It returns
+// ErrNotStarted, without starting new, if old is not running.
+func Handoff(old, new *Job, readyTimeout time.Duration) error {
+	if old.State() != StateRunning {
+		return ErrNotStarted
+	}
+	if e := new.validate(runOptions{}); e != nil {
+		return fmt.Errorf("new job: %w", e)
+	}
+	if _, _, _, e := new.start(runOptions{}); e != nil {
+		return fmt.Errorf("new job: %w", e)
+	}
+	new.mu.Lock()
+	l := new.l
+	new.mu.Unlock()
+
+	var timeout <-chan time.Time
+	if readyTimeout > 0 {
+		t := new.clock().NewTimer(readyTimeout)
+		defer t.Stop()
+		timeout = t.C()
+	}
+	select {
+	case <-l.ready:
+	case <-l.runDone:
+		select {
+		case <-l.ready:
+			// Run became ready before it returned.
+		default:
+			return errors.Join(errors.New("new job stopped before it was ready"), new.Wait())
+		}
+	case <-timeout:
+		new.logger().Printf("not ready after %v, keeping the old job", readyTimeout)
+		new.SignalToClose()
+		return errors.Join(ErrStartupTimeout, new.Wait())
+	}
+
+	old.logger().Printf("replacement ready, closing")
+	if e := old.SignalToClose(); e != nil {
+		return e
+	}
+	return old.Wait()
+}
@@ -0,0 +1,80 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jharshman/async"
+)
+
+func TestHandoff(t *testing.T) {
+	var oldClosed, newClosed bool
+	old := blockingJob(&oldClosed)
+	if err := old.Start(); err != nil {
+		t.Fatal(err)
+	}
+	next := &async.Job{
+		RunCtx: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		Close: func() error {
+			newClosed = true
+			return nil
+		},
+	}
+
+	if err := async.Handoff(old, next, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if !oldClosed {
+		t.Error("expected the old job to be closed")
+	}
+	if s := next.State(); s != async.StateRunning || newClosed {
+		t.Errorf("expected the new job to be left running, got %v", s)
+	}
+	if err := next.Stop(context.Background()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHandoffNotReady(t *testing.T) {
+	var oldClosed, newClosed bool
+	old := blockingJob(&oldClosed)
+	if err := old.Start(); err != nil {
+		t.Fatal(err)
+	}
+	stop := make(chan struct{})
+	next := &async.Job{
+		RunReady: func(ready func()) error {
+			<-stop
+			return nil
+		},
+		Close: func() error {
+			newClosed = true
+			close(stop)
+			return nil
+		},
+	}
+
+	// error expected here
+	if err := async.Handoff(old, next, time.Millisecond*50); !errors.Is(err, async.ErrStartupTimeout) {
+		t.Errorf("expected %v, got %v", async.ErrStartupTimeout, err)
+	}
+	if !newClosed {
+		t.Error("expected the new job to be closed")
+	}
+	if s := old.State(); s != async.StateRunning || oldClosed {
+		t.Errorf("expected the old job to be left running, got %v", s)
+	}
+	if err := old.Stop(context.Background()); err != nil {
+		t.Error(err)
+	}
+
+	// error expected here
+	if err := async.Handoff(old, next, time.Second); err != async.ErrNotStarted {
+		t.Errorf("expected %v, got %v", async.ErrNotStarted, err)
+	}
+}
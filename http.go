@@ -0,0 +1,83 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// HTTPServerJob returns a Job, configured by opts, that serves s with
+// ListenAndServe. http.ErrServerClosed, which ListenAndServe returns once
+// Shutdown has been called, is treated as a clean exit.
+//
+// Close calls Shutdown, which waits for active connections to go idle,
+// for up to shutdownTimeout, or indefinitely if it is zero. If that
+// elapses first, the remaining connections are closed with Close and
+// the timeout is reported.
+func HTTPServerJob(s *http.Server, shutdownTimeout time.Duration, opts ...Option) *Job {
+	j := &Job{
+		Run: func() error {
+			return serveHTTP(s.ListenAndServe)
+		},
+		CloseCtx: func(ctx context.Context) error {
+			return shutdownHTTP(ctx, s, shutdownTimeout)
+		},
+	}
+	for _, opt := range opts {
+		opt(j)
+	}
+	return j
+}
+
+// HTTPSServerJob is like HTTPServerJob, but serves s with
+// ListenAndServeTLS using the given certificate and key files. It
+// returns an error if either path is empty.
+func HTTPSServerJob(s *http.Server, certFile, keyFile string, shutdownTimeout time.Duration, opts ...Option) (*Job, error) {
+	if certFile == "" || keyFile == "" {
+		return nil, fmt.Errorf("HTTPSServerJob requires both certFile and keyFile")
+	}
+	j := HTTPServerJob(s, shutdownTimeout, opts...)
+	j.Run = func() error {
+		return serveHTTP(func() error {
+			return s.ListenAndServeTLS(certFile, keyFile)
+		})
+	}
+	return j, nil
+}
+
+// serverClosed reports whether e is http.ErrServerClosed returned once
+// the close path has begun: a server run by hand stopping because Close
+// called Shutdown, which is not a failure.
+func (l *lifecycle) serverClosed(e error) bool {
+	select {
+	case <-l.stop:
+		return errors.Is(e, http.ErrServerClosed)
+	default:
+		return false
+	}
+}
+
+// serveHTTP calls serve, treating http.ErrServerClosed as a clean exit.
+func serveHTTP(serve func() error) error {
+	if e := serve(); !errors.Is(e, http.ErrServerClosed) {
+		return e
+	}
+	return nil
+}
+
+// shutdownHTTP shuts s down within timeout, if positive, and within
+// ctx, closing the connections that remain if either is done first.
+func shutdownHTTP(ctx context.Context, s *http.Server, timeout time.Duration) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	e := s.Shutdown(ctx)
+	if e != nil && ctx.Err() != nil {
+		s.Close()
+	}
+	return e
+}
@@ -0,0 +1,160 @@
+package async_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jharshman/async"
+)
+
+func TestHTTPServerJob(t *testing.T) {
+	s := &http.Server{
+		Addr: "127.0.0.1:0",
+	}
+	job := async.HTTPServerJob(s, time.Second)
+	closeSoon(job)
+
+	// http.ErrServerClosed is not a run error
+	if err := job.Execute(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestJob_ExecuteServerClosed(t *testing.T) {
+	s := &http.Server{
+		Addr: "127.0.0.1:0",
+	}
+	job := &async.Job{
+		Run: func() error {
+			return s.ListenAndServe()
+		},
+		Close: func() error {
+			return s.Shutdown(context.Background())
+		},
+	}
+	closeSoon(job)
+
+	// http.ErrServerClosed after Shutdown is not a run error
+	if err := job.Execute(); err != nil {
+		t.Error(err)
+	}
+
+	// but it is before the close path has begun
+	job.Run = func() error {
+		return http.ErrServerClosed
+	}
+	// error expected here
+	if err := job.Execute(); !errors.Is(err, http.ErrServerClosed) {
+		t.Errorf("expected %v, got %v", http.ErrServerClosed, err)
+	}
+}
+
+func TestHTTPServerJobShutdownTimeout(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	handling := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	s := &http.Server{
+		Addr: lis.Addr().String(),
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(handling)
+			<-release
+		}),
+	}
+	lis.Close()
+	job := async.HTTPServerJob(s, time.Millisecond*50)
+
+	go func() {
+		// wait for the server to listen
+		for {
+			c, err := net.Dial("tcp", s.Addr)
+			if err == nil {
+				c.Close()
+				break
+			}
+			<-time.After(time.Millisecond)
+		}
+		// the request hangs, keeping its connection active
+		go http.Get("http://" + s.Addr)
+		<-handling
+		job.SignalToClose()
+	}()
+
+	// error expected here
+	if err := job.Execute(); err == nil {
+		t.Error("expected the shutdown to time out")
+	}
+}
+
+// writeCert writes a self-signed certificate and its key for 127.0.0.1
+// to dir, returning their paths.
+func writeCert(t *testing.T, dir string) (certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err := os.WriteFile(certFile, certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, keyPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestHTTPSServerJob(t *testing.T) {
+	certFile, keyFile := writeCert(t, t.TempDir())
+	s := &http.Server{
+		Addr: "127.0.0.1:0",
+	}
+	job, err := async.HTTPSServerJob(s, certFile, keyFile, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	closeSoon(job)
+
+	// http.ErrServerClosed is not a run error
+	if err := job.Execute(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHTTPSServerJobNoCert(t *testing.T) {
+	// error expected here
+	if _, err := async.HTTPSServerJob(&http.Server{}, "", "key.pem", time.Second); err == nil {
+		t.Error("expected an error without a certificate file")
+	}
+}
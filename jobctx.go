@@ -0,0 +1,57 @@
+package async
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+)
+
+// jobIDs numbers jobs in the order their ID is first asked for.
+var jobIDs atomic.Uint64
+
+// jobKey is the context key of the jobInfo added by withJob.
+type jobKey struct{}
+
+// jobInfo identifies the job a context was passed by.
+type jobInfo struct {
+	name string
+	id   string
+}
+
+// ID returns an identifier for the job, generated the first time it is
+// needed and unique within the process. Unlike Name, it is never empty.
+func (j *Job) ID() string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.idLocked()
+}
+
+// idLocked implements ID. j.mu must be held.
+func (j *Job) idLocked() string {
+	if j.id == "" {
+		j.id = strconv.FormatUint(jobIDs.Add(1), 10)
+	}
+	return j.id
+}
+
+// withJob returns ctx carrying the job's Name and ID, for the contexts
+// handed to the Ctx variants of Run and Close. j.mu must be held.
+func (j *Job) withJob(ctx context.Context) context.Context {
+	return context.WithValue(ctx, jobKey{}, jobInfo{name: j.Name, id: j.idLocked()})
+}
+
+// JobNameFromContext returns the Name of the job ctx was passed by, as
+// RunCtx, CloseCtx and the other functions of a Job taking a context
+// receive it, so shared code can tell which job it is running for. It
+// reports false if ctx does not come from a job. A job without a Name
+// has an empty one.
+func JobNameFromContext(ctx context.Context) (string, bool) {
+	info, ok := ctx.Value(jobKey{}).(jobInfo)
+	return info.name, ok
+}
+
+// JobIDFromContext is like JobNameFromContext, but returns the job's ID.
+func JobIDFromContext(ctx context.Context) (string, bool) {
+	info, ok := ctx.Value(jobKey{}).(jobInfo)
+	return info.id, ok
+}
@@ -0,0 +1,45 @@
+package async_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jharshman/async"
+)
+
+func TestJobNameFromContext(t *testing.T) {
+	var runName, closeName, runID, closeID string
+	job := &async.Job{
+		Name: "worker",
+		RunCtx: func(ctx context.Context) error {
+			runName, _ = async.JobNameFromContext(ctx)
+			runID, _ = async.JobIDFromContext(ctx)
+			<-ctx.Done()
+			return nil
+		},
+		CloseCtx: func(ctx context.Context) error {
+			closeName, _ = async.JobNameFromContext(ctx)
+			closeID, _ = async.JobIDFromContext(ctx)
+			return nil
+		},
+	}
+
+	closeSoon(job)
+	if err := job.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if runName != "worker" || closeName != "worker" {
+		t.Errorf("expected the job's name in both contexts, got %q and %q", runName, closeName)
+	}
+	if runID != job.ID() || closeID != job.ID() {
+		t.Errorf("expected ID %q in both contexts, got %q and %q", job.ID(), runID, closeID)
+	}
+	if other := (&async.Job{}).ID(); other == job.ID() {
+		t.Errorf("expected distinct jobs to have distinct IDs, both got %q", other)
+	}
+
+	// error expected here
+	if _, ok := async.JobNameFromContext(context.Background()); ok {
+		t.Error("expected no job name in a context not passed by a job")
+	}
+}
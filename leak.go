@@ -0,0 +1,53 @@
+package async
+
+import (
+	"runtime"
+	"sync/atomic"
+)
+
+// leakSentinel is referenced only by its Job, so it is collected along
+// with the job. Its finalizer reports the job as leaked unless the job
+// has been executed by then.
+type leakSentinel struct {
+	executed atomic.Bool
+}
+
+// WithLeakWarning arms a best-effort leak detector on the job: warn is
+// called if the job is garbage collected without ever having been
+// executed, started or passed over by ShouldRun, for instance because
+// it was built and then dropped. A job NewJob rejects is never handed
+// out, so it is not reported.
+//
+// Detection depends on the garbage collector. warn is called from the
+// finalizer goroutine at some point after a collection finds the job
+// unreachable, which may be late or, if the program exits first, never.
+// A job that is still running is kept reachable by its own goroutines
+// and so is never reported; a goroutine leak profile is the tool for
+// that.
+func WithLeakWarning(warn func()) Option {
+	return func(j *Job) {
+		s := &leakSentinel{}
+		runtime.SetFinalizer(s, func(s *leakSentinel) {
+			if !s.executed.Load() {
+				warn()
+			}
+		})
+		j.leak = s
+	}
+}
+
+// markExecuted records that the job has been put to use, so that
+// WithLeakWarning does not report it.
+func (j *Job) markExecuted() {
+	if j.leak != nil {
+		j.leak.executed.Store(true)
+	}
+}
+
+// disarmLeakWarning removes the finalizer set by WithLeakWarning, for a
+// job that is never handed out.
+func (j *Job) disarmLeakWarning() {
+	if j.leak != nil {
+		runtime.SetFinalizer(j.leak, nil)
+	}
+}
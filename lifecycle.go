@@ -0,0 +1,269 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// lifecycle holds the state shared by the run and close sides
+// of a single call to RunWithClose.
+type lifecycle struct {
+	cleanups cleanupStack
+	ctx      *graceContext
+	cancel   context.CancelFunc
+	stop     chan struct{}
+
+	// done is closed once the close path has finished.
+	done chan struct{}
+
+	// runDone is closed once the run goroutine has returned.
+	runDone chan struct{}
+
+	// ready is closed by the first call to markReady.
+	ready     chan struct{}
+	readyOnce sync.Once
+
+	// opts customizes this run.
+	opts runOptions
+
+	// skipClose is set when the run side asks for the close path to be
+	// skipped, per Job.SkipCloseOnPanic.
+	skipClose atomic.Bool
+
+	// closing is set once the user's close function has been called.
+	closing atomic.Bool
+
+	// executed is closed once Execute has moved on from a run it
+	// drives, by restarting the job or returning.
+	executed chan struct{}
+
+	// started is when the run was launched, by the job's clock.
+	started time.Time
+
+	mu   sync.Mutex
+	errs []error
+
+	// sig is the signal that closed the job, if any.
+	sig os.Signal
+
+	// reason is what triggered the close path, once hasReason is set.
+	reason    CloseReason
+	hasReason bool
+
+	// restart is set by Job.Restart, which receives the result of
+	// starting the job again on it.
+	restart chan error
+
+	// phases holds the phases of the close path completed so far, for
+	// RunWithPhases.
+	phases []PhaseAck
+
+	// deferredUntil is when a close deferred by Job.MinUptime takes
+	// effect, and is zero when none is pending.
+	deferredUntil time.Time
+
+	// restarts counts the restarts of Run, and lastErr is the latest
+	// error reported or restarted after, for Job.Status.
+	restarts int
+	lastErr  error
+}
+
+// runOptions customize a single run of a job without changing the Job,
+// so they never outlive the run they were given to.
+type runOptions struct {
+	// run, if set, takes the place of the job's run function. It is
+	// supplied by TypedJob.
+	run func() error
+
+	// restart, if set, is consulted after Run fails on the given attempt
+	// and reports whether to run it again. It is supplied by Supervisor
+	// and returns false once ctx is done.
+	restart func(ctx context.Context, attempt int, err error) bool
+
+	// workers, if positive, is the number of copies of the run function
+	// to run at once. It is supplied by RunN.
+	workers int
+
+	// executed is set for runs driven by Execute.
+	executed bool
+
+	// member is set for runs started by a JobGroup or Race, which follow
+	// the lifecycle they started and not one launched by Restart.
+	member bool
+
+	// phases, if set, receives the phase acks of the close path. It is
+	// supplied by RunWithPhases.
+	phases chan PhaseAck
+}
+
+func newLifecycle() *lifecycle {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &lifecycle{
+		ctx:      &graceContext{Context: ctx},
+		cancel:   cancel,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+		runDone:  make(chan struct{}),
+		ready:    make(chan struct{}),
+		executed: make(chan struct{}),
+	}
+}
+
+// markReady closes ready. It is safe to call more than once.
+func (l *lifecycle) markReady() {
+	l.readyOnce.Do(func() { close(l.ready) })
+}
+
+// stopRun cancels the run context and closes the stop channel together,
+// telling the run side that the close path has begun. A non-zero grace
+// becomes the run context's deadline first.
+func (l *lifecycle) stopRun(grace time.Time) {
+	if !grace.IsZero() {
+		l.ctx.setDeadline(grace)
+	}
+	l.cancel()
+	close(l.stop)
+}
+
+// graceContext is the context handed to the run function. Its deadline
+// is unset until the close path begins, when it is set to the end of the
+// grace period the run side has left to wind down.
+type graceContext struct {
+	context.Context
+
+	mu       sync.Mutex
+	deadline time.Time
+}
+
+func (c *graceContext) setDeadline(d time.Time) {
+	c.mu.Lock()
+	c.deadline = d
+	c.mu.Unlock()
+}
+
+// Deadline returns the end of the grace period once the close path has
+// begun with Job.CloseTimeout set.
+func (c *graceContext) Deadline() (time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.deadline, !c.deadline.IsZero()
+}
+
+// closeOnce calls fn, the user's close function, the first time it is
+// called and returns its error. Later calls return at once, however
+// many close triggers race; they do not wait for a close that is still
+// in flight, since it may be hung. They return nil, or ErrCloseRepeated
+// if strict is set.
+func (l *lifecycle) closeOnce(strict bool, fn func() error) error {
+	if !l.closing.CompareAndSwap(false, true) {
+		if strict {
+			return ErrCloseRepeated
+		}
+		return nil
+	}
+	return fn()
+}
+
+// finished reports whether both the close path and the run goroutine
+// have returned. A Run left hung by ErrCloseTimeout keeps the lifecycle
+// unfinished, so it is never run twice at once.
+func (l *lifecycle) finished() bool {
+	select {
+	case <-l.done:
+	default:
+		return false
+	}
+	select {
+	case <-l.runDone:
+		return true
+	default:
+		return false
+	}
+}
+
+// setSignal records s as the signal that closed the job.
+func (l *lifecycle) setSignal(s os.Signal) {
+	l.mu.Lock()
+	l.sig = s
+	l.mu.Unlock()
+}
+
+// setDeferred records when a close deferred by Job.MinUptime takes
+// effect, or that none is pending if t is zero.
+func (l *lifecycle) setDeferred(t time.Time) {
+	l.mu.Lock()
+	l.deferredUntil = t
+	l.mu.Unlock()
+}
+
+// signal returns the signal that closed the job, or nil.
+func (l *lifecycle) signal() os.Signal {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.sig
+}
+
+// setReason records r as the reason the job closes, unless a reason
+// has already been recorded.
+func (l *lifecycle) setReason(r CloseReason) {
+	l.mu.Lock()
+	if !l.hasReason {
+		l.reason, l.hasReason = r, true
+	}
+	l.mu.Unlock()
+}
+
+// closeReason returns the reason recorded by setReason, or
+// CloseRequested.
+func (l *lifecycle) closeReason() CloseReason {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.reason
+}
+
+// requestRestart marks the run as closing to be restarted, returning the
+// channel the result of the restart is sent on. It reports false if a
+// restart has already been requested.
+func (l *lifecycle) requestRestart() (chan error, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.restart != nil {
+		return nil, false
+	}
+	l.restart = make(chan error, 1)
+	return l.restart, true
+}
+
+// restartRequested returns the channel set by requestRestart, or nil.
+func (l *lifecycle) restartRequested() chan error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.restart
+}
+
+// record keeps e so it can be returned by Job.Wait.
+func (l *lifecycle) record(e error) {
+	l.mu.Lock()
+	l.errs = append(l.errs, e)
+	l.lastErr = e
+	l.mu.Unlock()
+}
+
+// restarted counts a restart of Run after it failed with e.
+func (l *lifecycle) restarted(e error) {
+	l.mu.Lock()
+	l.restarts++
+	l.lastErr = e
+	l.mu.Unlock()
+}
+
+// err returns the recorded errors joined.
+func (l *lifecycle) err() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return errors.Join(l.errs...)
+}
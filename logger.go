@@ -0,0 +1,20 @@
+package async
+
+// Logger is used by Job to log lifecycle transitions.
+// It is satisfied by *log.Logger.
+type Logger interface {
+	Printf(format string, args ...any)
+}
+
+// nopLogger discards everything logged to it.
+type nopLogger struct{}
+
+func (nopLogger) Printf(format string, args ...any) {}
+
+// logger returns Job.Logger, or a no-op Logger if it is unset.
+func (j *Job) logger() Logger {
+	if j.Logger != nil {
+		return j.Logger
+	}
+	return nopLogger{}
+}
@@ -0,0 +1,33 @@
+package async
+
+import "time"
+
+// Metrics receives measurements of a Job's lifecycle, to be forwarded to
+// a metrics backend through a thin adapter.
+type Metrics interface {
+	// ObserveRunDuration is called each time the run function returns,
+	// with how long that attempt ran.
+	ObserveRunDuration(d time.Duration)
+
+	// IncRestart is called each time a Supervisor restarts Run.
+	IncRestart()
+
+	// ObserveCloseDuration is called once the close path has finished
+	// or timed out, with how long it took.
+	ObserveCloseDuration(d time.Duration)
+}
+
+// nopMetrics discards every measurement.
+type nopMetrics struct{}
+
+func (nopMetrics) ObserveRunDuration(d time.Duration)   {}
+func (nopMetrics) IncRestart()                          {}
+func (nopMetrics) ObserveCloseDuration(d time.Duration) {}
+
+// metrics returns Job.Metrics, or a no-op Metrics if it is unset.
+func (j *Job) metrics() Metrics {
+	if j.Metrics != nil {
+		return j.Metrics
+	}
+	return nopMetrics{}
+}
@@ -0,0 +1,69 @@
+package async_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jharshman/async"
+)
+
+// fakeMetrics counts the measurements it receives.
+type fakeMetrics struct {
+	mu       sync.Mutex
+	runs     int
+	restarts int
+	closes   int
+}
+
+func (m *fakeMetrics) ObserveRunDuration(d time.Duration) {
+	m.mu.Lock()
+	m.runs++
+	m.mu.Unlock()
+}
+
+func (m *fakeMetrics) IncRestart() {
+	m.mu.Lock()
+	m.restarts++
+	m.mu.Unlock()
+}
+
+func (m *fakeMetrics) ObserveCloseDuration(d time.Duration) {
+	m.mu.Lock()
+	m.closes++
+	m.mu.Unlock()
+}
+
+func TestJob_Metrics(t *testing.T) {
+	m := &fakeMetrics{}
+	runs := 0
+	job := &async.Job{
+		Run: func() error {
+			runs++
+			if runs == 1 {
+				return errors.New("some error")
+			}
+			return nil
+		},
+		Close: func() error {
+			return nil
+		},
+		Metrics: m,
+	}
+	s := async.Supervisor{
+		Job:         job,
+		MaxRestarts: 1,
+	}
+
+	err := s.Execute()
+	if err != nil {
+		t.Error(err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.runs != 2 || m.restarts != 1 || m.closes != 1 {
+		t.Errorf("expected 2 runs, 1 restart and 1 close, got %d, %d and %d", m.runs, m.restarts, m.closes)
+	}
+}
@@ -0,0 +1,39 @@
+package async
+
+import "time"
+
+// RunMiddleware decorates a run function, typically to add behavior
+// before and after calling next.
+type RunMiddleware func(next func() error) func() error
+
+// WrapRun returns run decorated by mws, the first of which is the
+// outermost, so it sees the call first and the result last. The result
+// is suitable for Job.Run.
+func WrapRun(run func() error, mws ...RunMiddleware) func() error {
+	for i := len(mws) - 1; i >= 0; i-- {
+		run = mws[i](run)
+	}
+	return run
+}
+
+// WithRecovery is a RunMiddleware that converts a panic in next into an
+// error carrying the stack trace, as the job does for its own functions.
+func WithRecovery(next func() error) func() error {
+	return func() error {
+		return recovered("Run", next)
+	}
+}
+
+// WithTiming returns a RunMiddleware that passes report how long each
+// call to next took, whether or not it failed.
+func WithTiming(report func(d time.Duration)) RunMiddleware {
+	return func(next func() error) func() error {
+		return func() error {
+			start := time.Now()
+			defer func() {
+				report(time.Since(start))
+			}()
+			return next()
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package async_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jharshman/async"
+)
+
+func TestWrapRun_Order(t *testing.T) {
+	var calls []string
+	trace := func(name string) async.RunMiddleware {
+		return func(next func() error) func() error {
+			return func() error {
+				calls = append(calls, name+" before")
+				err := next()
+				calls = append(calls, name+" after")
+				return err
+			}
+		}
+	}
+
+	run := async.WrapRun(func() error {
+		calls = append(calls, "run")
+		return nil
+	}, trace("outer"), trace("inner"))
+	if err := run(); err != nil {
+		t.Error(err)
+	}
+
+	want := "outer before,inner before,run,inner after,outer after"
+	if got := strings.Join(calls, ","); got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestWithRecovery(t *testing.T) {
+	job := async.Job{
+		Run: async.WrapRun(func() error {
+			panic("boom")
+		}, async.WithRecovery),
+		Close: func() error {
+			return nil
+		},
+	}
+
+	// error expected here
+	err := job.Execute()
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("expected the panic as an error, got %v", err)
+	}
+}
+
+func TestWithTiming(t *testing.T) {
+	errRun := errors.New("run failed")
+	var took time.Duration
+	run := async.WrapRun(func() error {
+		time.Sleep(10 * time.Millisecond)
+		return errRun
+	}, async.WithTiming(func(d time.Duration) {
+		took = d
+	}))
+
+	// error expected here
+	if err := run(); err != errRun {
+		t.Errorf("expected %v, got %v", errRun, err)
+	}
+	if took < 10*time.Millisecond {
+		t.Errorf("expected at least 10ms, got %v", took)
+	}
+}
@@ -0,0 +1,99 @@
+package async
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+)
+
+// notifySignals and stopSignals relay OS signals to Execute and
+// JobGroup.Run. They are variables so tests can deliver signals without
+// sending real ones to the test process.
+var (
+	notifySignals = signals.notify
+	stopSignals   = signals.stop
+)
+
+// signals is the broker every job's signal registrations go through.
+var signals = &signalBroker{}
+
+// signalBroker registers each signal with package signal once, however
+// many jobs are notified on it, and relays it to all of them. Like
+// signal.Notify, it never blocks sending to a full channel, and once
+// stop has returned, nothing more is sent on the stopped channel.
+type signalBroker struct {
+	mu sync.Mutex
+
+	// relays holds the registration of each signal at least one channel
+	// is notified on.
+	relays map[os.Signal]*signalRelay
+}
+
+// signalRelay is the single registration of one signal.
+type signalRelay struct {
+	in   chan os.Signal
+	quit chan struct{}
+	subs map[chan<- os.Signal]bool
+}
+
+// notify relays each of sig to c. Unlike signal.Notify, at least one
+// signal must be given.
+func (b *signalBroker) notify(c chan<- os.Signal, sig ...os.Signal) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.relays == nil {
+		b.relays = map[os.Signal]*signalRelay{}
+	}
+	for _, s := range sig {
+		r := b.relays[s]
+		if r == nil {
+			r = &signalRelay{
+				in:   make(chan os.Signal, closeSignals),
+				quit: make(chan struct{}),
+				subs: map[chan<- os.Signal]bool{},
+			}
+			b.relays[s] = r
+			signal.Notify(r.in, s)
+			go b.relay(r)
+		}
+		r.subs[c] = true
+	}
+}
+
+// stop stops relaying signals to c, releasing the registration of each
+// signal no other channel is notified on.
+func (b *signalBroker) stop(c chan<- os.Signal) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for s, r := range b.relays {
+		if !r.subs[c] {
+			continue
+		}
+		delete(r.subs, c)
+		if len(r.subs) == 0 {
+			signal.Stop(r.in)
+			close(r.quit)
+			delete(b.relays, s)
+		}
+	}
+}
+
+// relay sends each signal received by r to its subscribers until r is
+// released.
+func (b *signalBroker) relay(r *signalRelay) {
+	for {
+		select {
+		case s := <-r.in:
+			b.mu.Lock()
+			for c := range r.subs {
+				select {
+				case c <- s:
+				default:
+				}
+			}
+			b.mu.Unlock()
+		case <-r.quit:
+			return
+		}
+	}
+}
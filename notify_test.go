@@ -0,0 +1,388 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// fakeSignals stubs notifySignals and stopSignals for the rest of the
+// test. It returns a function that delivers s as if the process had
+// received it, once something is notified on s.
+func fakeSignals(t *testing.T) func(s os.Signal) {
+	notify, stop := notifySignals, stopSignals
+	t.Cleanup(func() {
+		notifySignals, stopSignals = notify, stop
+	})
+
+	var mu sync.Mutex
+	notified := map[chan<- os.Signal][]os.Signal{}
+	notifySignals = func(c chan<- os.Signal, sig ...os.Signal) {
+		mu.Lock()
+		notified[c] = append(notified[c], sig...)
+		mu.Unlock()
+	}
+	stopSignals = func(c chan<- os.Signal) {
+		mu.Lock()
+		delete(notified, c)
+		mu.Unlock()
+	}
+
+	return func(s os.Signal) {
+		deadline := time.Now().Add(time.Second * 2)
+		for time.Now().Before(deadline) {
+			mu.Lock()
+			for c, sigs := range notified {
+				for _, n := range sigs {
+					if n == s {
+						mu.Unlock()
+						// like package signal, never block on a full channel
+						select {
+						case c <- s:
+						default:
+						}
+						return
+					}
+				}
+			}
+			mu.Unlock()
+			<-time.After(time.Millisecond)
+		}
+		t.Errorf("expected something to be notified on %v", s)
+	}
+}
+
+func TestJob_ExecuteFakeSignal(t *testing.T) {
+	deliver := fakeSignals(t)
+	job := Job{
+		RunCtx: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		Close: func() error {
+			return nil
+		},
+	}
+
+	go deliver(syscall.SIGTERM)
+
+	err := job.Execute()
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestJob_ExecuteFakeSignalForced(t *testing.T) {
+	deliver := fakeSignals(t)
+	closing := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	job := Job{
+		RunCtx: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		Close: func() error {
+			close(closing)
+			<-release
+			return nil
+		},
+	}
+
+	go func() {
+		deliver(syscall.SIGTERM)
+		<-closing
+		deliver(syscall.SIGINT)
+	}()
+
+	// error expected here
+	err := job.Execute()
+	if !errors.Is(err, ErrForcedShutdown) {
+		t.Errorf("expected ErrForcedShutdown, got %v", err)
+	}
+}
+
+func TestJob_ExecuteFakeSignalEscalated(t *testing.T) {
+	deliver := fakeSignals(t)
+	deferred := make(chan struct{})
+	closed := false
+	job := Job{
+		RunCtx: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		Close: func() error {
+			closed = true
+			return nil
+		},
+		MinUptime: time.Hour,
+		OnClosingSoon: func(time.Duration) {
+			close(deferred)
+		},
+	}
+
+	go func() {
+		deliver(syscall.SIGTERM)
+		<-deferred
+		deliver(syscall.SIGINT)
+	}()
+
+	// the second signal skips MinUptime rather than forcing shutdown
+	if err := job.Execute(); err != nil {
+		t.Error(err)
+	}
+	if !closed {
+		t.Error("expected Close to be called")
+	}
+	if s := job.State(); s != StateClosed {
+		t.Errorf("expected the job to be closed, got %v", s)
+	}
+}
+
+func TestJob_ExecuteFakeSignalEscalatedCloseTimeout(t *testing.T) {
+	deliver := fakeSignals(t)
+	deferred := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	job := Job{
+		RunCtx: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		Close: func() error {
+			<-release
+			return nil
+		},
+		CloseTimeout: time.Millisecond * 50,
+		MinUptime:    time.Hour,
+		OnClosingSoon: func(time.Duration) {
+			close(deferred)
+		},
+	}
+
+	go func() {
+		deliver(syscall.SIGTERM)
+		<-deferred
+		deliver(syscall.SIGINT)
+	}()
+
+	// error expected here
+	err := job.Execute()
+	if !errors.Is(err, ErrForcedShutdown) || !errors.Is(err, ErrCloseTimeout) {
+		t.Errorf("expected ErrForcedShutdown and ErrCloseTimeout, got %v", err)
+	}
+}
+
+func TestJob_ExecuteFakeSignalBackToBack(t *testing.T) {
+	deliver := fakeSignals(t)
+	release := make(chan struct{})
+	defer close(release)
+	job := Job{
+		RunCtx: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		Close: func() error {
+			<-release
+			return nil
+		},
+		SignalHandlers: map[os.Signal]func(){
+			// Execute is busy here, so both signals are pending at once
+			syscall.SIGUSR1: func() {
+				deliver(syscall.SIGTERM)
+				deliver(syscall.SIGINT)
+			},
+		},
+	}
+
+	go deliver(syscall.SIGUSR1)
+	result := make(chan error, 1)
+	go func() {
+		result <- job.Execute()
+	}()
+
+	select {
+	case err := <-result:
+		// error expected here
+		if !errors.Is(err, ErrForcedShutdown) {
+			t.Errorf("expected ErrForcedShutdown, got %v", err)
+		}
+	case <-time.After(time.Second * 2):
+		t.Fatal("expected the second signal to force shutdown")
+	}
+}
+
+func TestJob_ExecuteCoalescedTriggers(t *testing.T) {
+	deliver := fakeSignals(t)
+	var closes atomic.Int32
+	job := Job{
+		RunCtx: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+	}
+	job.Close = func() error {
+		closes.Add(1)
+		// triggers arriving once the close has begun are ignored
+		job.SignalToClose()
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		deliver(syscall.SIGTERM)
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				job.SignalToClose()
+			}()
+		}
+		cancel()
+		wg.Wait()
+	}()
+
+	if err := job.ExecuteContext(ctx); err != nil {
+		t.Error(err)
+	}
+	if n := closes.Load(); n != 1 {
+		t.Errorf("expected Close to be called once, got %d", n)
+	}
+	if s := job.State(); s != StateClosed {
+		t.Errorf("expected the job to be closed, got %v", s)
+	}
+}
+
+func TestJob_ExecuteFakeSignalCloseError(t *testing.T) {
+	deliver := fakeSignals(t)
+	errClose := errors.New("close failed")
+	handling := make(chan struct{})
+	job := Job{
+		RunCtx: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		// Close keeps Execute busy in a signal handler while the close
+		// error and ack are sent, so both are ready once it looks again.
+		Close: func() error {
+			go deliver(syscall.SIGUSR1)
+			<-handling
+			return errClose
+		},
+		SignalHandlers: map[os.Signal]func(){
+			syscall.SIGUSR1: func() {
+				handling <- struct{}{}
+				<-time.After(time.Millisecond * 20)
+			},
+		},
+	}
+
+	for i := 0; i < 20; i++ {
+		go deliver(syscall.SIGTERM)
+
+		// error expected here
+		err := job.Execute()
+		if !errors.Is(err, errClose) {
+			t.Fatalf("run %d: expected %v, got %v", i, errClose, err)
+		}
+	}
+}
+
+func TestSignalBroker(t *testing.T) {
+	b := &signalBroker{}
+	first, second := make(chan os.Signal, 1), make(chan os.Signal, 1)
+	b.notify(first, syscall.SIGUSR1)
+	b.notify(second, syscall.SIGUSR1, syscall.SIGUSR2)
+	if n := len(b.relays); n != 2 {
+		t.Errorf("expected 2 registered signals, got %d", n)
+	}
+
+	receive := func(c chan os.Signal) bool {
+		select {
+		case <-c:
+			return true
+		case <-time.After(time.Millisecond * 200):
+			return false
+		}
+	}
+
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	if !receive(first) || !receive(second) {
+		t.Error("expected SIGUSR1 to reach both channels")
+	}
+
+	b.stop(first)
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	if !receive(second) {
+		t.Error("expected SIGUSR1 to still reach the second channel")
+	}
+	if receive(first) {
+		t.Error("expected nothing on the stopped channel")
+	}
+
+	b.stop(second)
+	if n := len(b.relays); n != 0 {
+		t.Errorf("expected every signal to be released, got %d", n)
+	}
+}
+
+func TestShutdownAllOnSignal(t *testing.T) {
+	deliver := fakeSignals(t)
+	var mu sync.Mutex
+	var closed []string
+	newGroup := func(name string) (*JobGroup, chan struct{}) {
+		started := make(chan struct{})
+		stopped := make(chan struct{})
+		g := &JobGroup{
+			NoSignals: true,
+		}
+		g.Add(&Job{
+			Run: func() error {
+				<-stopped
+				return nil
+			},
+			Close: func() error {
+				mu.Lock()
+				closed = append(closed, name)
+				mu.Unlock()
+				close(stopped)
+				return nil
+			},
+			OnStart: func() {
+				close(started)
+			},
+		})
+		return g, started
+	}
+	first, firstStarted := newGroup("first")
+	second, secondStarted := newGroup("second")
+
+	results := make(chan error, 2)
+	go func() { results <- first.Run() }()
+	go func() { results <- second.Run() }()
+	<-firstStarted
+	<-secondStarted
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- ShutdownAllOnSignal(nil, second, first) }()
+	deliver(syscall.SIGTERM)
+
+	if err := <-shutdown; err != nil {
+		t.Error(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := <-results; err != nil {
+			t.Error(err)
+		}
+	}
+	if !reflect.DeepEqual(closed, []string{"second", "first"}) {
+		t.Errorf("expected groups to close in order, got %v", closed)
+	}
+}
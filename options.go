@@ -0,0 +1,54 @@
+package async
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Option configures a Job created by NewJob.
+type Option func(*Job)
+
+// NewJob returns a Job with the given Run and Close functions, configured
+// by opts. Unlike a struct literal, the job is validated up front: an
+// error is returned if run or close is nil or the options leave the job
+// invalid.
+func NewJob(run, close func() error, opts ...Option) (*Job, error) {
+	if run == nil || close == nil {
+		return nil, fmt.Errorf("either Run or Close fields missing")
+	}
+
+	j := &Job{
+		Run:   run,
+		Close: close,
+	}
+	for _, opt := range opts {
+		opt(j)
+	}
+	if e := j.Validate(); e != nil {
+		j.disarmLeakWarning()
+		return nil, e
+	}
+	return j, nil
+}
+
+// WithSignals sets Job.Signals.
+func WithSignals(signals ...os.Signal) Option {
+	return func(j *Job) {
+		j.Signals = signals
+	}
+}
+
+// WithCloseTimeout sets Job.CloseTimeout.
+func WithCloseTimeout(d time.Duration) Option {
+	return func(j *Job) {
+		j.CloseTimeout = d
+	}
+}
+
+// WithLogger sets Job.Logger.
+func WithLogger(l Logger) Option {
+	return func(j *Job) {
+		j.Logger = l
+	}
+}
@@ -0,0 +1,153 @@
+package async_test
+
+import (
+	"log"
+	"os"
+	"reflect"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/jharshman/async"
+)
+
+func TestNewJob(t *testing.T) {
+	logger := log.New(os.Stderr, "", 0)
+	job, err := async.NewJob(
+		func() error { return nil },
+		func() error { return nil },
+		async.WithSignals(syscall.SIGHUP),
+		async.WithCloseTimeout(time.Second),
+		async.WithLogger(logger),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(job.Signals, []os.Signal{syscall.SIGHUP}) {
+		t.Errorf("expected Signals to be set, got %v", job.Signals)
+	}
+	if job.CloseTimeout != time.Second {
+		t.Errorf("expected CloseTimeout to be set, got %v", job.CloseTimeout)
+	}
+	if job.Logger != logger {
+		t.Error("expected Logger to be set")
+	}
+}
+
+func TestNewJobNoCloseDefined(t *testing.T) {
+	// error expected here
+	_, err := async.NewJob(func() error { return nil }, nil)
+	if err == nil {
+		t.Error(err)
+	}
+}
+
+func TestNewJobUncatchableSignal(t *testing.T) {
+	// error expected here
+	_, err := async.NewJob(
+		func() error { return nil },
+		func() error { return nil },
+		async.WithSignals(syscall.SIGKILL),
+	)
+	if err == nil {
+		t.Error(err)
+	}
+}
+
+// collect runs the garbage collector until leaked receives or a second
+// has passed, reporting whether it received.
+func collect(leaked chan struct{}) bool {
+	deadline := time.After(time.Second)
+	for {
+		runtime.GC()
+		select {
+		case <-leaked:
+			return true
+		case <-deadline:
+			return false
+		case <-time.After(time.Millisecond * 10):
+		}
+	}
+}
+
+func TestNewJobWithLeakWarning(t *testing.T) {
+	leaked := make(chan struct{}, 1)
+	func() {
+		_, err := async.NewJob(
+			func() error { return nil },
+			func() error { return nil },
+			async.WithLeakWarning(func() { leaked <- struct{}{} }),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	if !collect(leaked) {
+		t.Error("expected a warning for a job abandoned without executing")
+	}
+}
+
+func TestNewJobWithLeakWarningExecuted(t *testing.T) {
+	leaked := make(chan struct{}, 1)
+	func() {
+		job, err := async.NewJob(
+			func() error { return nil },
+			func() error { return nil },
+			async.WithLeakWarning(func() { leaked <- struct{}{} }),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := job.Execute(); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	if collect(leaked) {
+		t.Error("expected no warning for a job that was executed")
+	}
+}
+
+func TestNewJobWithLeakWarningRejected(t *testing.T) {
+	leaked := make(chan struct{}, 1)
+	func() {
+		// error expected here
+		_, err := async.NewJob(
+			func() error { return nil },
+			func() error { return nil },
+			async.WithLeakWarning(func() { leaked <- struct{}{} }),
+			async.WithSignals(syscall.SIGKILL),
+		)
+		if err == nil {
+			t.Fatal("expected NewJob to reject SIGKILL")
+		}
+	}()
+
+	if collect(leaked) {
+		t.Error("expected no warning for a job NewJob rejected")
+	}
+}
+
+func TestNewJobWithLeakWarningSkipped(t *testing.T) {
+	leaked := make(chan struct{}, 1)
+	func() {
+		job, err := async.NewJob(
+			func() error { return nil },
+			func() error { return nil },
+			async.WithLeakWarning(func() { leaked <- struct{}{} }),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		job.ShouldRun = func() bool { return false }
+		if err := job.Execute(); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	if collect(leaked) {
+		t.Error("expected no warning for a job skipped by ShouldRun")
+	}
+}
@@ -0,0 +1,82 @@
+package async
+
+// Phase is a stage of a job's close path, reported by RunWithPhases.
+type Phase int
+
+const (
+	// PhaseDrain is completed once Job.Drain has returned, or at once if
+	// Drain is not set.
+	PhaseDrain Phase = iota
+	// PhaseClose is completed once Close, the closers added with
+	// AddCloser and the RunWithCleanup cleanups have all returned.
+	PhaseClose
+	// PhaseFinally is completed once Job.Finally has returned, or at
+	// once if Finally is not set. It is the last phase.
+	PhaseFinally
+)
+
+func (p Phase) String() string {
+	switch p {
+	case PhaseDrain:
+		return "drain"
+	case PhaseClose:
+		return "close"
+	case PhaseFinally:
+		return "finally"
+	}
+	return "unknown"
+}
+
+// PhaseAck reports a completed Phase of the close path, with the error
+// it ended with, if any.
+type PhaseAck struct {
+	Phase Phase
+	Err   error
+}
+
+// RunWithPhases is like RunWithClose, but also returns a channel that
+// acknowledges each phase of the close path as it completes, in order,
+// so a caller can follow a multi-stage teardown. The final ack of 1 is
+// still sent on "ack" once the close path has finished, after the phase
+// acks. Errors are reported on "err" as well, as RunWithClose does.
+//
+// Phases skipped by SkipCloseOnPanic are not acknowledged, and if Close
+// times out, the phases that had not completed are not either.
+//
+// The close path waits for room on phases to send each ack, so the
+// caller must receive every one of them; see Job.AckBuffer.
+func (j *Job) RunWithPhases() (sig, ack chan int, err chan error, phases chan PhaseAck) {
+	phases = make(chan PhaseAck, j.ackBuffer())
+	sig, ack, err, e := j.start(runOptions{phases: phases})
+	if e != nil {
+		sig, ack, err = make(chan int, 1), make(chan int, 1), make(chan error, 1)
+		err <- e
+	}
+	return
+}
+
+// completed records that phase p of the close path has ended with e,
+// for RunWithPhases.
+func (l *lifecycle) completed(p Phase, e error) {
+	if l.opts.phases == nil {
+		return
+	}
+	l.mu.Lock()
+	l.phases = append(l.phases, PhaseAck{Phase: p, Err: e})
+	l.mu.Unlock()
+}
+
+// ackPhases sends the phases completed so far, and then PhaseFinally,
+// on the channel returned by RunWithPhases.
+func (l *lifecycle) ackPhases() {
+	if l.opts.phases == nil {
+		return
+	}
+	l.mu.Lock()
+	acks := l.phases
+	l.mu.Unlock()
+	for _, a := range acks {
+		l.opts.phases <- a
+	}
+	l.opts.phases <- PhaseAck{Phase: PhaseFinally}
+}
@@ -0,0 +1,93 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jharshman/async"
+)
+
+func TestJob_RunWithPhases(t *testing.T) {
+	errDrain := errors.New("drain failed")
+	stopped := make(chan struct{})
+	job := async.Job{
+		Run: func() error {
+			<-stopped
+			return nil
+		},
+		Drain: func(ctx context.Context) error {
+			return errDrain
+		},
+		Close: func() error {
+			close(stopped)
+			return nil
+		},
+		Finally: func() {},
+	}
+
+	sig, ack, err, phases := job.RunWithPhases()
+	sig <- 1
+
+	var got []async.Phase
+	for a := range phases {
+		got = append(got, a.Phase)
+		if a.Phase == async.PhaseDrain && !errors.Is(a.Err, errDrain) {
+			t.Errorf("expected %v with the drain phase, got %v", errDrain, a.Err)
+		}
+		if a.Phase != async.PhaseDrain && a.Err != nil {
+			t.Errorf("expected no error with the %v phase, got %v", a.Phase, a.Err)
+		}
+		if a.Phase == async.PhaseFinally {
+			break
+		}
+	}
+	want := []async.Phase{async.PhaseDrain, async.PhaseClose, async.PhaseFinally}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected phases %v, got %v", want, got)
+	}
+
+	if a := <-ack; a != 1 {
+		t.Errorf("expected a final ack of 1, got %d", a)
+	}
+	// error expected here
+	if e := <-err; !errors.Is(e, errDrain) {
+		t.Errorf("expected %v, got %v", errDrain, e)
+	}
+}
+
+func TestJob_AckBuffer(t *testing.T) {
+	stopped := make(chan struct{})
+	job := async.Job{
+		Run: func() error {
+			<-stopped
+			return nil
+		},
+		Close: func() error {
+			close(stopped)
+			return nil
+		},
+		AckBuffer: 3,
+	}
+
+	// nothing reads the phase acks until the job is done, which would
+	// block the close path with room for just one
+	sig, ack, _, phases := job.RunWithPhases()
+	sig <- 1
+	select {
+	case <-job.Done():
+	case <-time.After(time.Second):
+		t.Fatal("expected the close path to finish without the acks being read")
+	}
+
+	for _, want := range []async.Phase{async.PhaseDrain, async.PhaseClose, async.PhaseFinally} {
+		if a := <-phases; a.Phase != want {
+			t.Errorf("expected the %v phase, got %v", want, a.Phase)
+		}
+	}
+	if a := <-ack; a != 1 {
+		t.Errorf("expected a final ack of 1, got %d", a)
+	}
+}
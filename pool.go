@@ -0,0 +1,68 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// NumCPU, passed to RunN, runs as many workers as DefaultWorkerCount.
+const NumCPU = -1
+
+// DefaultWorkerCount returns the number of workers that keeps every
+// processor busy, the current runtime.GOMAXPROCS setting.
+func DefaultWorkerCount() int {
+	return runtime.GOMAXPROCS(0)
+}
+
+// RunN runs the job as Execute does, but with n copies of its run
+// function working side by side under the one lifecycle. The workers
+// share the run context, which is cancelled when the job is signaled to
+// close or when any worker fails, so the job must use RunCtx or
+// RunWithStop. Close is called once, after every worker has stopped,
+// and the workers' errors are joined.
+//
+// An n of NumCPU is resolved to DefaultWorkerCount each time RunN is
+// called, so it follows changes to GOMAXPROCS between runs.
+func (j *Job) RunN(n int) error {
+	if n == NumCPU {
+		n = DefaultWorkerCount()
+	}
+	if n < 1 {
+		return fmt.Errorf("RunN requires at least one worker, got %d", n)
+	}
+	if j.RunCtx == nil && j.RunWithStop == nil {
+		return fmt.Errorf("RunN requires RunCtx or RunWithStop")
+	}
+	return j.executeChain(nil, runOptions{workers: n})
+}
+
+// runWorkers runs l.opts.workers copies of the job's run function and
+// waits for them all to return.
+func (j *Job) runWorkers(ctx context.Context, l *lifecycle) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs := make([]error, l.opts.workers)
+	var wg sync.WaitGroup
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			e := recovered("Run", func() error {
+				if j.RunCtx != nil {
+					return j.RunCtx(ctx)
+				}
+				return j.RunWithStop(ctx, l.stop)
+			})
+			if e != nil {
+				errs[i] = fmt.Errorf("worker %d: %w", i, e)
+				cancel()
+			}
+		}(i)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
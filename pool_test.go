@@ -0,0 +1,113 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jharshman/async"
+)
+
+func TestJob_RunN(t *testing.T) {
+	var running, stopped, closes atomic.Int32
+	job := async.Job{}
+	job.RunCtx = func(ctx context.Context) error {
+		if running.Add(1) == 3 {
+			job.SignalToClose()
+		}
+		<-ctx.Done()
+		stopped.Add(1)
+		return nil
+	}
+	job.Close = func() error {
+		if n := stopped.Load(); n != 3 {
+			t.Errorf("expected Close after all 3 workers stopped, %d had", n)
+		}
+		closes.Add(1)
+		return nil
+	}
+
+	if err := job.RunN(3); err != nil {
+		t.Error(err)
+	}
+	if n := closes.Load(); n != 1 {
+		t.Errorf("expected Close to be called once, got %d", n)
+	}
+}
+
+func TestJob_RunNJoinsErrors(t *testing.T) {
+	errA, errB := errors.New("a"), errors.New("b")
+	var worker atomic.Int32
+	job := async.Job{
+		RunCtx: func(ctx context.Context) error {
+			if worker.Add(1) == 1 {
+				return errA
+			}
+			// the failure of the first worker stops the rest
+			<-ctx.Done()
+			return errB
+		},
+		Close: func() error {
+			return nil
+		},
+	}
+
+	// error expected here
+	err := job.RunN(2)
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Errorf("expected both worker errors to be joined, got %v", err)
+	}
+}
+
+func TestJob_RunNInvalid(t *testing.T) {
+	job := async.Job{
+		Run: func() error {
+			return nil
+		},
+		Close: func() error {
+			return nil
+		},
+	}
+
+	// error expected here
+	if err := job.RunN(2); err == nil {
+		t.Error("expected an error for RunN without RunCtx or RunWithStop")
+	}
+	job.RunCtx = func(context.Context) error {
+		return nil
+	}
+	// error expected here
+	if err := job.RunN(0); err == nil {
+		t.Error("expected an error for zero workers")
+	}
+}
+
+func TestJob_RunNNumCPU(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(3))
+	if n := async.DefaultWorkerCount(); n != 3 {
+		t.Errorf("expected DefaultWorkerCount to follow GOMAXPROCS, got %d", n)
+	}
+
+	var running atomic.Int32
+	job := async.Job{
+		Close: func() error {
+			return nil
+		},
+	}
+	job.RunCtx = func(ctx context.Context) error {
+		if running.Add(1) == 3 {
+			job.SignalToClose()
+		}
+		<-ctx.Done()
+		return nil
+	}
+
+	if err := job.RunN(async.NumCPU); err != nil {
+		t.Error(err)
+	}
+	if n := running.Load(); n != 3 {
+		t.Errorf("expected GOMAXPROCS workers, got %d", n)
+	}
+}
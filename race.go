@@ -0,0 +1,68 @@
+package async
+
+import "fmt"
+
+// Race starts each of jobs as RunWithClose does and waits for the first
+// Run to return, successfully or not. It then closes all the others and
+// waits for every job to finish closing. Race returns the errors of the
+// job whose Run returned first, as Job.Wait would; those of the others
+// can be read with their own Wait.
+//
+// If a job fails to start, the jobs started before it are closed and the
+// error is returned, wrapped with the job's position.
+func Race(jobs ...*Job) error {
+	if len(jobs) == 0 {
+		return nil
+	}
+
+	sigs := make([]chan int, 0, len(jobs))
+	lives := make([]*lifecycle, 0, len(jobs))
+	closeAll := func(except int) {
+		for i, sig := range sigs {
+			if i != except {
+				signalClose(sig)
+			}
+		}
+		for _, l := range lives {
+			<-l.done
+		}
+	}
+
+	for i, j := range jobs {
+		e := j.validate(runOptions{})
+		if e == nil && j.ShouldRun != nil {
+			e = fmt.Errorf("ShouldRun not supported by Race")
+		}
+		if e == nil {
+			var sig chan int
+			sig, _, _, e = j.start(runOptions{member: true})
+			if e == nil {
+				j.mu.Lock()
+				lives = append(lives, j.l)
+				j.mu.Unlock()
+				sigs = append(sigs, sig)
+				continue
+			}
+		}
+		closeAll(-1)
+		return fmt.Errorf("job %d: %w", i, e)
+	}
+
+	first := make(chan int, len(lives))
+	for i, l := range lives {
+		i, l := i, l
+		go func() {
+			// A job closed from elsewhere may finish without Run
+			// returning, if its Close timed out.
+			select {
+			case <-l.runDone:
+			case <-l.done:
+			}
+			first <- i
+		}()
+	}
+	winner := <-first
+	jobs[winner].logger().Printf("run returned first, closing the other jobs")
+	closeAll(winner)
+	return lives[winner].err()
+}
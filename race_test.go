@@ -0,0 +1,98 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jharshman/async"
+)
+
+func TestRace(t *testing.T) {
+	errRun := errors.New("run failed")
+	var closed atomic.Int32
+	blocking := func() *async.Job {
+		return &async.Job{
+			RunCtx: func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			},
+			Close: func() error {
+				closed.Add(1)
+				return nil
+			},
+		}
+	}
+	failing := &async.Job{
+		Run: func() error {
+			return errRun
+		},
+		Close: func() error {
+			closed.Add(1)
+			return nil
+		},
+	}
+	jobs := []*async.Job{blocking(), failing, blocking()}
+
+	// error expected here
+	if err := async.Race(jobs...); !errors.Is(err, errRun) {
+		t.Errorf("expected %v, got %v", errRun, err)
+	}
+	if n := closed.Load(); n != 3 {
+		t.Errorf("expected every job to be closed, got %d", n)
+	}
+	for i, j := range jobs {
+		if s := j.State(); s != async.StateClosed && s != async.StateFailed {
+			t.Errorf("expected job %d to have finished, got %v", i, s)
+		}
+	}
+}
+
+func TestRaceStartFails(t *testing.T) {
+	closed := false
+	started := &async.Job{
+		RunCtx: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+		Close: func() error {
+			closed = true
+			return nil
+		},
+	}
+
+	// error expected here
+	if err := async.Race(started, &async.Job{}); err == nil {
+		t.Error("expected an error for the job without Run or Close")
+	}
+	if !closed {
+		t.Error("expected the job started before the failure to be closed")
+	}
+	if err := async.Race(); err != nil {
+		t.Errorf("expected no error racing no jobs, got %v", err)
+	}
+}
+
+func TestRaceShouldRun(t *testing.T) {
+	job := &async.Job{
+		Run: func() error {
+			return nil
+		},
+		Close: func() error {
+			return nil
+		},
+		ShouldRun: func() bool {
+			return false
+		},
+	}
+
+	// error expected here
+	if err := async.Race(job); err == nil || !strings.Contains(err.Error(), "ShouldRun") {
+		t.Errorf("expected ShouldRun to be rejected, got %v", err)
+	}
+	if s := job.State(); s != async.StateIdle {
+		t.Errorf("expected the job not to be started, got %v", s)
+	}
+}
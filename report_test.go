@@ -0,0 +1,79 @@
+package async
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// fullErrors returns an err channel that has already been filled.
+func fullErrors() chan error {
+	err := make(chan error, 1)
+	err <- errors.New("first")
+	return err
+}
+
+func TestJob_reportWaitsForExecute(t *testing.T) {
+	var j Job
+	l := newLifecycle()
+	l.opts.executed = true
+	err := fullErrors()
+	second := errors.New("second")
+
+	reported := make(chan struct{})
+	go func() {
+		j.report(l, err, second)
+		close(reported)
+	}()
+
+	// Execute reads err late, but still receives the error
+	<-time.After(time.Millisecond * 50)
+	<-err
+	select {
+	case e := <-err:
+		if e != second {
+			t.Errorf("expected %v, got %v", second, e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the error to be sent once there was room")
+	}
+	<-reported
+}
+
+func TestJob_reportAfterExecute(t *testing.T) {
+	var j Job
+	l := newLifecycle()
+	l.opts.executed = true
+	err := fullErrors()
+
+	reported := make(chan struct{})
+	go func() {
+		j.report(l, err, errors.New("second"))
+		close(reported)
+	}()
+	close(l.executed)
+
+	select {
+	case <-reported:
+	case <-time.After(time.Second):
+		t.Fatal("expected report to give up once Execute had moved on")
+	}
+}
+
+func TestJob_reportRunWithClose(t *testing.T) {
+	var j Job
+	l := newLifecycle()
+	err := fullErrors()
+
+	reported := make(chan struct{})
+	go func() {
+		j.report(l, err, errors.New("second"))
+		close(reported)
+	}()
+
+	select {
+	case <-reported:
+	case <-time.After(time.Second):
+		t.Fatal("expected report not to block a run started by RunWithClose")
+	}
+}
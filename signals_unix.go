@@ -0,0 +1,21 @@
+//go:build !windows
+// +build !windows
+
+package async
+
+import (
+	"os"
+	"syscall"
+)
+
+// defaultSignals are notified on when no signals are configured.
+var defaultSignals = []os.Signal{
+	syscall.SIGINT,
+	syscall.SIGTERM,
+}
+
+// uncatchableSignals are never delivered to signal.Notify.
+var uncatchableSignals = []os.Signal{
+	syscall.SIGKILL,
+	syscall.SIGSTOP,
+}
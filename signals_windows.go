@@ -0,0 +1,20 @@
+//go:build windows
+// +build windows
+
+package async
+
+import (
+	"os"
+	"syscall"
+)
+
+// defaultSignals are notified on when no signals are configured. Only
+// os.Interrupt, raised by Ctrl+C, is reliably delivered on Windows.
+var defaultSignals = []os.Signal{
+	os.Interrupt,
+}
+
+// uncatchableSignals are never delivered to signal.Notify.
+var uncatchableSignals = []os.Signal{
+	syscall.SIGKILL,
+}
@@ -0,0 +1,54 @@
+package async
+
+import (
+	"context"
+	"time"
+)
+
+// defaultStartupGrace is used when StartupRetry.Grace is zero.
+const defaultStartupGrace = 5 * time.Second
+
+// StartupRetry retries Run when it fails while the job is still starting
+// up, for instance because a database it connects to is not up yet. Run
+// counts as starting up until it first runs for longer than Grace; a
+// failure after that is a genuine runtime failure, left to a Supervisor
+// if there is one.
+type StartupRetry struct {
+	// Attempts is how many times Run is tried before a startup failure
+	// is reported. Values below 2 disable startup retries.
+	Attempts int
+
+	// Backoff returns the delay before the given retry, counting from 1.
+	// A nil Backoff retries immediately.
+	Backoff func(retry int) time.Duration
+
+	// Grace is how long Run must run before a failure no longer counts
+	// as a startup failure. Defaults to 5 seconds.
+	Grace time.Duration
+}
+
+func (r StartupRetry) grace() time.Duration {
+	if r.Grace > 0 {
+		return r.Grace
+	}
+	return defaultStartupGrace
+}
+
+// wait waits out the backoff before the given retry by clock, reporting
+// false if ctx is done first, meaning the job has started closing.
+func (r StartupRetry) wait(ctx context.Context, clock Clock, retry int) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if r.Backoff == nil {
+		return true
+	}
+	t := clock.NewTimer(r.Backoff(retry))
+	defer t.Stop()
+	select {
+	case <-t.C():
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
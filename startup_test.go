@@ -0,0 +1,94 @@
+package async_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jharshman/async"
+)
+
+func TestJob_StartupRetry(t *testing.T) {
+	var runs int
+	var backoffs []int
+	job := async.Job{
+		Run: func() error {
+			runs++
+			if runs < 3 {
+				return errors.New("database not up yet")
+			}
+			return nil
+		},
+		Close: func() error {
+			return nil
+		},
+		StartupRetry: async.StartupRetry{
+			Attempts: 3,
+			Backoff: func(retry int) time.Duration {
+				backoffs = append(backoffs, retry)
+				return time.Millisecond
+			},
+		},
+	}
+
+	if err := job.Execute(); err != nil {
+		t.Error(err)
+	}
+	if runs != 3 {
+		t.Errorf("expected 3 runs, got %d", runs)
+	}
+	if !reflect.DeepEqual(backoffs, []int{1, 2}) {
+		t.Errorf("expected backoff before retries 1 and 2, got %v", backoffs)
+	}
+}
+
+func TestJob_StartupRetryExhausted(t *testing.T) {
+	var runs int
+	job := async.Job{
+		Run: func() error {
+			runs++
+			return errors.New("database not up yet")
+		},
+		Close: func() error {
+			return nil
+		},
+		StartupRetry: async.StartupRetry{
+			Attempts: 2,
+		},
+	}
+
+	// error expected here
+	if err := job.Execute(); err == nil {
+		t.Error("expected the startup failure")
+	}
+	if runs != 2 {
+		t.Errorf("expected 2 runs, got %d", runs)
+	}
+}
+
+func TestJob_StartupRetryAfterGrace(t *testing.T) {
+	var runs int
+	job := async.Job{
+		Run: func() error {
+			runs++
+			<-time.After(time.Millisecond * 50)
+			return errors.New("runtime failure")
+		},
+		Close: func() error {
+			return nil
+		},
+		StartupRetry: async.StartupRetry{
+			Attempts: 3,
+			Grace:    time.Millisecond * 20,
+		},
+	}
+
+	// error expected here
+	if err := job.Execute(); err == nil {
+		t.Error("expected the runtime failure")
+	}
+	if runs != 1 {
+		t.Errorf("expected a failure after Grace not to be retried, got %d runs", runs)
+	}
+}
@@ -0,0 +1,85 @@
+package async
+
+// State is the lifecycle state of a Job.
+type State int32
+
+const (
+	// StateIdle is the state of a job that has not been started.
+	StateIdle State = iota
+	// StateRunning is the state of a job whose Run has been launched.
+	StateRunning
+	// StateClosing is the state of a job whose close path has begun.
+	StateClosing
+	// StateClosed is the state of a job that closed without errors.
+	StateClosed
+	// StateFailed is the state of a job that finished closing after
+	// Run or Close reported an error, or whose Close timed out.
+	StateFailed
+)
+
+func (s State) String() string {
+	switch s {
+	case StateIdle:
+		return "idle"
+	case StateRunning:
+		return "running"
+	case StateClosing:
+		return "closing"
+	case StateClosed:
+		return "closed"
+	case StateFailed:
+		return "failed"
+	}
+	return "unknown"
+}
+
+// State returns the job's current lifecycle state.
+// It is safe to call concurrently while the job runs.
+func (j *Job) State() State {
+	return State(j.state.Load())
+}
+
+// stateChangesBuffer is the capacity of the channel returned by
+// StateChanges, enough for several runs of transitions.
+const stateChangesBuffer = 16
+
+// StateChanges returns a channel on which each new state of the job is
+// sent, in order, starting from the next transition. Every call returns
+// the same channel, which is never closed.
+//
+// The channel is buffered. Sends never block the job: once the buffer is
+// full, further states are dropped until the consumer catches up, so a
+// consumer that must see every transition should use OnStateChange.
+func (j *Job) StateChanges() <-chan State {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.stateChanges == nil {
+		j.stateChanges = make(chan State, stateChangesBuffer)
+	}
+	return j.stateChanges
+}
+
+// setState records s as the job's current state and reports it.
+func (j *Job) setState(s State) {
+	j.state.Store(int32(s))
+	j.notifyState(s)
+}
+
+// notifyState reports s to Job.OnStateChange and on the StateChanges
+// channel. It must not be called with j.mu held.
+func (j *Job) notifyState(s State) {
+	if j.OnStateChange != nil {
+		j.OnStateChange(s)
+	}
+
+	j.mu.Lock()
+	changes := j.stateChanges
+	j.mu.Unlock()
+	if changes == nil {
+		return
+	}
+	select {
+	case changes <- s:
+	default:
+	}
+}
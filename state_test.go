@@ -0,0 +1,96 @@
+package async_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/jharshman/async"
+)
+
+func TestJob_State(t *testing.T) {
+	stopped := make(chan struct{})
+	closing := make(chan async.State, 1)
+	job := &async.Job{
+		Run: func() error {
+			<-stopped
+			return nil
+		},
+	}
+	job.Close = func() error {
+		closing <- job.State()
+		close(stopped)
+		return nil
+	}
+
+	if s := job.State(); s != async.StateIdle {
+		t.Errorf("expected %v, got %v", async.StateIdle, s)
+	}
+
+	job.RunWithClose()
+	if s := job.State(); s != async.StateRunning {
+		t.Errorf("expected %v, got %v", async.StateRunning, s)
+	}
+
+	job.SignalToClose()
+	if s := <-closing; s != async.StateClosing {
+		t.Errorf("expected %v, got %v", async.StateClosing, s)
+	}
+
+	job.Wait()
+	if s := job.State(); s != async.StateClosed {
+		t.Errorf("expected %v, got %v", async.StateClosed, s)
+	}
+}
+
+func TestJob_StateFailed(t *testing.T) {
+	job := async.Job{
+		Run: func() error {
+			return errors.New("some error")
+		},
+		Close: func() error {
+			return nil
+		},
+	}
+
+	job.Execute()
+	if s := job.State(); s != async.StateFailed {
+		t.Errorf("expected %v, got %v", async.StateFailed, s)
+	}
+}
+
+func TestJob_StateChanges(t *testing.T) {
+	var seen []async.State
+	job := async.Job{
+		Run: func() error {
+			return nil
+		},
+		Close: func() error {
+			return nil
+		},
+		OnStateChange: func(s async.State) {
+			seen = append(seen, s)
+		},
+	}
+	changes := job.StateChanges()
+
+	err := job.Execute()
+	if err != nil {
+		t.Error(err)
+	}
+
+	want := []async.State{async.StateRunning, async.StateClosing, async.StateClosed}
+	if !reflect.DeepEqual(seen, want) {
+		t.Errorf("expected OnStateChange with %v, got %v", want, seen)
+	}
+	for _, s := range want {
+		if got := <-changes; got != s {
+			t.Errorf("expected %v on StateChanges, got %v", s, got)
+		}
+	}
+	select {
+	case s := <-changes:
+		t.Errorf("expected no further states, got %v", s)
+	default:
+	}
+}
@@ -0,0 +1,63 @@
+package async
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Status is a snapshot of a job's latest run.
+type Status struct {
+	Name  string `json:"name"`
+	State string `json:"state"`
+
+	// StartedAt is when the latest run was launched, and is zero if the
+	// job has not been started.
+	StartedAt time.Time `json:"started_at"`
+
+	// Restarts counts the times a Supervisor restarted Run during the
+	// latest run.
+	Restarts int `json:"restarts"`
+
+	// LastError is the latest error the job reported or restarted
+	// after, if any.
+	LastError string `json:"last_error,omitempty"`
+}
+
+// Status returns a snapshot of the job's latest run. It is safe to call
+// concurrently while the job runs.
+func (j *Job) Status() Status {
+	j.mu.Lock()
+	l := j.l
+	j.mu.Unlock()
+
+	s := Status{
+		Name:  j.Name,
+		State: j.State().String(),
+	}
+	if l == nil {
+		return s
+	}
+	s.StartedAt = l.started
+	l.mu.Lock()
+	s.Restarts = l.restarts
+	if l.lastErr != nil {
+		s.LastError = l.lastErr.Error()
+	}
+	l.mu.Unlock()
+	return s
+}
+
+// StatusJSON returns the Status of each member of the group, in the
+// order they were added, as a JSON array. Members without a Name are
+// named after their position, as in "job 0".
+func (g *JobGroup) StatusJSON() ([]byte, error) {
+	statuses := make([]Status, len(g.jobs))
+	for i, j := range g.jobs {
+		statuses[i] = j.Status()
+		if statuses[i].Name == "" {
+			statuses[i].Name = fmt.Sprintf("job %d", i)
+		}
+	}
+	return json.Marshal(statuses)
+}
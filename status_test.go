@@ -0,0 +1,107 @@
+package async_test
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/jharshman/async"
+)
+
+func TestJob_Status(t *testing.T) {
+	job := async.Job{
+		Name: "worker",
+		Run: func() error {
+			return errors.New("some error")
+		},
+		Close: func() error {
+			return nil
+		},
+	}
+	if s := job.Status(); s.State != "idle" || !s.StartedAt.IsZero() {
+		t.Errorf("expected an idle job without a start time, got %+v", s)
+	}
+
+	// error expected here
+	job.Execute()
+
+	s := job.Status()
+	if s.Name != "worker" || s.State != "failed" || s.StartedAt.IsZero() {
+		t.Errorf("expected a failed job with a start time, got %+v", s)
+	}
+	if s.LastError != "some error" {
+		t.Errorf("expected last error, got %q", s.LastError)
+	}
+}
+
+func TestJob_StatusRestarts(t *testing.T) {
+	var runs int
+	s := async.Supervisor{
+		Job: &async.Job{
+			Run: func() error {
+				runs++
+				if runs < 3 {
+					return errors.New("flaky")
+				}
+				return nil
+			},
+			Close: func() error {
+				return nil
+			},
+		},
+		MaxRestarts: 5,
+	}
+	if err := s.Execute(); err != nil {
+		t.Error(err)
+	}
+
+	got := s.Job.Status()
+	if got.Restarts != 2 || got.LastError != "flaky" {
+		t.Errorf("expected 2 restarts after flaky, got %+v", got)
+	}
+}
+
+func TestJobGroup_StatusJSON(t *testing.T) {
+	var namedClosed, otherClosed bool
+	named := blockingJob(&namedClosed)
+	named.Name = "api"
+	other := blockingJob(&otherClosed)
+	// members are started in order, so both have started by then
+	started := make(chan struct{})
+	other.OnStart = func() {
+		close(started)
+	}
+	g := &async.JobGroup{
+		Signals: []os.Signal{syscall.SIGUSR2},
+	}
+	g.Add(named)
+	g.Add(other)
+
+	result := make(chan error, 1)
+	go func() { result <- g.Run() }()
+	<-started
+
+	b, err := g.StatusJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var statuses []async.Status
+	if err := json.Unmarshal(b, &statuses); err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 2 || statuses[0].Name != "api" || statuses[1].Name != "job 1" {
+		t.Errorf("expected api and job 1, got %s", b)
+	}
+	for _, s := range statuses {
+		if s.State != "running" {
+			t.Errorf("expected %s to be running, got %s", s.Name, s.State)
+		}
+	}
+
+	if err := async.ShutdownAll(g); err != nil {
+		t.Error(err)
+	}
+	<-result
+}
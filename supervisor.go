@@ -0,0 +1,191 @@
+package async
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Supervisor executes a Job, restarting its Run function when it
+// returns an error instead of shutting the job down.
+//
+// A signal or any other close trigger still closes the job as usual
+// and stops further restarts. Each run of a RunCtx restarted by the
+// supervisor is handed a fresh context, and the context of the run
+// before it is cancelled by the time it starts.
+type Supervisor struct {
+	Job *Job
+
+	// MaxRestarts is the number of times Run is restarted after failing.
+	// Once exhausted, the last error from Run is returned. A negative
+	// value places no limit on the total number of restarts.
+	MaxRestarts int
+
+	// RestartWindow and MaxRestartsInWindow rate limit restarts: if Run
+	// would be restarted more than MaxRestartsInWindow times within any
+	// RestartWindow, the supervisor gives up and returns the last error.
+	// This stops a Run that fails immediately from restarting in a tight
+	// loop. A zero RestartWindow disables the limit.
+	RestartWindow       time.Duration
+	MaxRestartsInWindow int
+
+	// Backoff returns the delay before the given restart attempt,
+	// counting from 1. A nil Backoff restarts immediately.
+	Backoff func(attempt int) time.Duration
+
+	// mu guards state.
+	mu    sync.Mutex
+	state RestartState
+}
+
+// RestartState describes where a Supervisor is in its restart loop.
+type RestartState struct {
+	// Attempt is the number of the latest failed attempt of Run, counting
+	// from 1, or 0 if Run has not failed.
+	Attempt int
+
+	// NextDelay is the backoff before the restart that follows Attempt.
+	// It is zero if that restart was denied or has no backoff.
+	NextDelay time.Duration
+
+	// LastFailure is when Run last failed.
+	LastFailure time.Time
+}
+
+// RestartState returns the supervisor's current restart state, for the
+// latest run of its job. It is safe to call while the job runs.
+func (s *Supervisor) RestartState() RestartState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state
+}
+
+// newRestarter resets the restart state and returns a restarter for a
+// new run of the job, drawing on shared if it is set.
+func (s *Supervisor) newRestarter(shared *restartBudget) *restarter {
+	s.mu.Lock()
+	s.state = RestartState{}
+	s.mu.Unlock()
+	return &restarter{Supervisor: s, shared: shared}
+}
+
+// setState records the state of the restart loop.
+func (s *Supervisor) setState(attempt int, delay time.Duration, failed time.Time) {
+	s.mu.Lock()
+	s.state = RestartState{
+		Attempt:     attempt,
+		NextDelay:   delay,
+		LastFailure: failed,
+	}
+	s.mu.Unlock()
+}
+
+// Execute calls Execute on the supervised job, restarting Run on failure
+// according to the supervisor's policy. The policy applies to this run
+// of the job only; the Job itself is left untouched.
+func (s *Supervisor) Execute() error {
+	r := s.newRestarter(nil)
+	return s.Job.executeChain(nil, runOptions{restart: r.restart})
+}
+
+// ExecuteContext is Execute, but the job is also closed when ctx is
+// done, as by Job.ExecuteContext.
+func (s *Supervisor) ExecuteContext(ctx context.Context) error {
+	r := s.newRestarter(nil)
+	return s.Job.executeChain(ctx.Done(), runOptions{restart: r.restart})
+}
+
+// restarter applies a Supervisor's policy to a single run of its job.
+type restarter struct {
+	*Supervisor
+
+	// restarts holds the times of recent restarts within RestartWindow.
+	restarts []time.Time
+
+	// shared, if set, is a budget the restart must also fit within.
+	shared *restartBudget
+}
+
+// restart reports whether Run should be restarted after failing on the
+// given attempt, waiting out the backoff first. It gives up if ctx is
+// done, meaning the job has started closing.
+func (s *restarter) restart(ctx context.Context, attempt int, err error) bool {
+	now := s.Job.clock().Now()
+	s.setState(attempt, 0, now)
+	if ctx.Err() != nil {
+		return false
+	}
+	if s.MaxRestarts >= 0 && attempt > s.MaxRestarts {
+		return false
+	}
+	if !s.allowRestart(now) {
+		return false
+	}
+	if s.shared != nil && !s.shared.allow(now) {
+		return false
+	}
+	if s.Backoff == nil {
+		return true
+	}
+
+	delay := s.Backoff(attempt)
+	s.setState(attempt, delay, now)
+	t := s.Job.clock().NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-t.C():
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// allowRestart records a restart at now and reports whether it stays
+// within MaxRestartsInWindow for the trailing RestartWindow.
+func (s *restarter) allowRestart(now time.Time) bool {
+	if s.RestartWindow <= 0 {
+		return true
+	}
+
+	recent := s.restarts[:0]
+	for _, t := range s.restarts {
+		if now.Sub(t) < s.RestartWindow {
+			recent = append(recent, t)
+		}
+	}
+	s.restarts = append(recent, now)
+	return len(s.restarts) <= s.MaxRestartsInWindow
+}
+
+// restartBudget is a restart limit shared by several supervised jobs:
+// at most max restarts in total within any trailing window, or over its
+// whole lifetime if window is zero.
+type restartBudget struct {
+	max    int
+	window time.Duration
+
+	mu       sync.Mutex
+	restarts []time.Time
+}
+
+// allow reports whether a restart at now fits within the budget,
+// recording it if so.
+func (b *restartBudget) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.window > 0 {
+		recent := b.restarts[:0]
+		for _, t := range b.restarts {
+			if now.Sub(t) < b.window {
+				recent = append(recent, t)
+			}
+		}
+		b.restarts = recent
+	}
+	if len(b.restarts) >= b.max {
+		return false
+	}
+	b.restarts = append(b.restarts, now)
+	return true
+}
@@ -0,0 +1,251 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jharshman/async"
+)
+
+func TestSupervisor_Execute(t *testing.T) {
+	runs := 0
+	stopped := make(chan struct{})
+	job := &async.Job{
+		Close: func() error {
+			close(stopped)
+			return nil
+		},
+	}
+	job.Run = func() error {
+		runs++
+		if runs == 1 {
+			return errors.New("some error")
+		}
+		closeSoon(job)
+		<-stopped
+		return nil
+	}
+
+	s := async.Supervisor{
+		Job:         job,
+		MaxRestarts: 3,
+	}
+
+	err := s.Execute()
+	if err != nil {
+		t.Error(err)
+	}
+	if runs != 2 {
+		t.Errorf("expected Run to be restarted once, ran %d times", runs)
+	}
+}
+
+func TestSupervisor_ExecuteFreshContext(t *testing.T) {
+	var ctxs []context.Context
+	job := &async.Job{
+		Close: func() error {
+			return nil
+		},
+	}
+	job.RunCtx = func(ctx context.Context) error {
+		if n := len(ctxs); n > 0 && ctxs[n-1].Err() == nil {
+			t.Errorf("expected the context of attempt %d to be done before attempt %d", n, n+1)
+		}
+		if ctx.Err() != nil {
+			t.Errorf("expected attempt %d to get a live context", len(ctxs)+1)
+		}
+		ctxs = append(ctxs, ctx)
+		if len(ctxs) < 3 {
+			return errors.New("some error")
+		}
+		closeSoon(job)
+		<-ctx.Done()
+		return nil
+	}
+
+	s := async.Supervisor{
+		Job:         job,
+		MaxRestarts: 3,
+	}
+	if err := s.Execute(); err != nil {
+		t.Error(err)
+	}
+	if len(ctxs) != 3 {
+		t.Errorf("expected 3 attempts, got %d", len(ctxs))
+	}
+}
+
+func TestSupervisor_ExecuteMaxRestarts(t *testing.T) {
+	runs := 0
+	var delays []int
+	s := async.Supervisor{
+		Job: &async.Job{
+			Run: func() error {
+				runs++
+				return errors.New("some error")
+			},
+			Close: func() error {
+				return nil
+			},
+		},
+		MaxRestarts: 2,
+		Backoff: func(attempt int) time.Duration {
+			delays = append(delays, attempt)
+			return time.Millisecond * 10
+		},
+	}
+
+	// error expected here
+	err := s.Execute()
+	if err == nil {
+		t.Error(err)
+	}
+	if runs != 3 {
+		t.Errorf("expected Run to run 3 times, ran %d times", runs)
+	}
+	if len(delays) != 2 || delays[0] != 1 || delays[1] != 2 {
+		t.Errorf("expected backoff for attempts 1 and 2, got %v", delays)
+	}
+}
+
+func TestSupervisor_ExecuteRestartWindow(t *testing.T) {
+	runs := 0
+	s := async.Supervisor{
+		Job: &async.Job{
+			Run: func() error {
+				runs++
+				return errors.New("some error")
+			},
+			Close: func() error {
+				return nil
+			},
+		},
+		MaxRestarts:         -1,
+		RestartWindow:       time.Minute,
+		MaxRestartsInWindow: 3,
+	}
+
+	// error expected here
+	err := s.Execute()
+	if err == nil {
+		t.Error(err)
+	}
+	if runs != 4 {
+		t.Errorf("expected Run to run 4 times, ran %d times", runs)
+	}
+}
+
+func TestSupervisor_ExecuteAlreadyStarted(t *testing.T) {
+	runs := 0
+	job := &async.Job{
+		Run: func() error {
+			runs++
+			return errors.New("some error")
+		},
+		Close: func() error {
+			return nil
+		},
+	}
+	_, ack, _ := job.RunWithClose()
+
+	s := async.Supervisor{
+		Job:         job,
+		MaxRestarts: 3,
+	}
+
+	// error expected here
+	err := s.Execute()
+	if err != async.ErrAlreadyStarted {
+		t.Errorf("expected ErrAlreadyStarted, got %v", err)
+	}
+	<-ack
+
+	// the supervisor's policy must not leak onto the running job
+	if runs != 1 {
+		t.Errorf("expected Run not to be restarted, ran %d times", runs)
+	}
+}
+
+func TestSupervisor_RestartState(t *testing.T) {
+	runs := 0
+	stopped := make(chan struct{})
+	job := &async.Job{
+		Close: func() error {
+			close(stopped)
+			return nil
+		},
+	}
+	s := &async.Supervisor{
+		Job:         job,
+		MaxRestarts: 3,
+		Backoff: func(attempt int) time.Duration {
+			return time.Millisecond * time.Duration(attempt*10)
+		},
+	}
+	var state async.RestartState
+	job.Run = func() error {
+		runs++
+		if runs <= 2 {
+			return errors.New("some error")
+		}
+		state = s.RestartState()
+		closeSoon(job)
+		<-stopped
+		return nil
+	}
+
+	started := time.Now()
+	err := s.Execute()
+	if err != nil {
+		t.Error(err)
+	}
+	if state.Attempt != 2 {
+		t.Errorf("expected attempt 2, got %d", state.Attempt)
+	}
+	if state.NextDelay != time.Millisecond*20 {
+		t.Errorf("expected next delay of 20ms, got %v", state.NextDelay)
+	}
+	if state.LastFailure.Before(started) {
+		t.Errorf("expected last failure during Execute, got %v", state.LastFailure)
+	}
+}
+
+func TestJob_RecentErrors(t *testing.T) {
+	var runs int
+	s := async.Supervisor{
+		Job: &async.Job{
+			Run: func() error {
+				runs++
+				if runs <= 4 {
+					return fmt.Errorf("failure %d", runs)
+				}
+				return nil
+			},
+			Close: func() error {
+				return nil
+			},
+			ErrHistory: 3,
+		},
+		MaxRestarts: 5,
+	}
+	if err := s.Execute(); err != nil {
+		t.Error(err)
+	}
+
+	recent := s.Job.RecentErrors()
+	var got []string
+	for i, e := range recent {
+		got = append(got, e.Err.Error())
+		if i > 0 && e.Time.Before(recent[i-1].Time) {
+			t.Error("expected errors oldest first")
+		}
+	}
+	want := []string{"failure 2", "failure 3", "failure 4"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
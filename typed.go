@@ -0,0 +1,49 @@
+package async
+
+import (
+	"fmt"
+	"sync"
+)
+
+// TypedJob is a Job whose Run function produces a value, which is
+// returned from Execute once the job has closed.
+// TypedJob.Run takes the place of Job.Run, so none of the embedded
+// Job's run functions may be set; all of its other fields apply as
+// usual.
+type TypedJob[T any] struct {
+	Job
+
+	Run func() (T, error)
+}
+
+// Execute runs the job as Job.Execute does, returning the value produced
+// by Run along with any error. If Run has not returned by the time the
+// job closes, the zero value is returned.
+func (t *TypedJob[T]) Execute() (T, error) {
+	var (
+		mu     sync.Mutex
+		result T
+	)
+
+	if t.Run == nil {
+		return result, fmt.Errorf("either Run or Close fields missing")
+	}
+	j := &t.Job
+	if j.Run != nil || j.RunCtx != nil || j.RunWithStop != nil || j.RunWithCleanup != nil || j.RunReady != nil || j.RunControlled != nil {
+		return result, fmt.Errorf("TypedJob.Run cannot be combined with a run function on Job")
+	}
+
+	err := j.executeChain(nil, runOptions{
+		run: func() error {
+			v, err := t.Run()
+			mu.Lock()
+			result = v
+			mu.Unlock()
+			return err
+		},
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+	return result, err
+}
@@ -0,0 +1,113 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jharshman/async"
+)
+
+func TestTypedJob_Execute(t *testing.T) {
+	job := async.TypedJob[int]{
+		Job: async.Job{
+			Close: func() error {
+				return nil
+			},
+		},
+		Run: func() (int, error) {
+			return 42, nil
+		},
+	}
+
+	v, err := job.Execute()
+	if err != nil {
+		t.Error(err)
+	}
+	if v != 42 {
+		t.Errorf("expected 42, got %d", v)
+	}
+}
+
+func TestTypedJob_ExecuteWithErrors(t *testing.T) {
+	job := async.TypedJob[string]{
+		Job: async.Job{
+			Close: func() error {
+				return nil
+			},
+		},
+		Run: func() (string, error) {
+			return "partial", errors.New("some error")
+		},
+	}
+
+	// error expected here
+	v, err := job.Execute()
+	if err == nil {
+		t.Error(err)
+	}
+	if v != "partial" {
+		t.Errorf("expected partial result, got %q", v)
+	}
+}
+
+func TestTypedJob_ExecuteNoRunDefined(t *testing.T) {
+	job := async.TypedJob[int]{
+		Job: async.Job{
+			Close: func() error {
+				return nil
+			},
+		},
+	}
+
+	// error expected here
+	_, err := job.Execute()
+	if err == nil {
+		t.Error(err)
+	}
+}
+
+func TestTypedJob_ExecuteRunOnJob(t *testing.T) {
+	job := async.TypedJob[int]{
+		Job: async.Job{
+			RunCtx: func(ctx context.Context) error {
+				return nil
+			},
+			Close: func() error {
+				return nil
+			},
+		},
+		Run: func() (int, error) {
+			return 42, nil
+		},
+	}
+
+	// error expected here
+	_, err := job.Execute()
+	if err == nil {
+		t.Error(err)
+	}
+}
+
+func TestTypedJob_ExecuteLeavesJob(t *testing.T) {
+	job := async.TypedJob[int]{
+		Job: async.Job{
+			Close: func() error {
+				return nil
+			},
+		},
+		Run: func() (int, error) {
+			return 42, nil
+		},
+	}
+
+	if _, err := job.Execute(); err != nil {
+		t.Error(err)
+	}
+	if job.Job.Run != nil {
+		t.Error("expected Execute not to set Job.Run")
+	}
+	if _, err := job.Execute(); err != nil {
+		t.Error(err)
+	}
+}